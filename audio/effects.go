@@ -0,0 +1,48 @@
+// audio/effects.go
+package audio
+
+import (
+	"time"
+)
+
+// Effect is a short sound giving feedback while studying.
+type Effect int
+
+const (
+	EffectCorrect  Effect = iota // a card was answered right
+	EffectWrong                  // a card was answered wrong
+	EffectComplete               // a study session is over
+)
+
+// effectVolume keeps feedback quieter than Morse code.
+const effectVolume = 0.4
+
+// note is a tone of an effect.
+type note struct {
+	freq   float64
+	length time.Duration
+}
+
+// effectNotes are the notes of each effect, played one after the other.
+var effectNotes = map[Effect][]note{
+	// A rising fifth, A5 to E6
+	EffectCorrect: {{880, 70 * time.Millisecond}, {1318.51, 140 * time.Millisecond}},
+	// A falling major third, A3 to F3
+	EffectWrong: {{220, 120 * time.Millisecond}, {174.61, 240 * time.Millisecond}},
+	// A C major arpeggio
+	EffectComplete: {
+		{523.25, 100 * time.Millisecond},
+		{659.25, 100 * time.Millisecond},
+		{783.99, 100 * time.Millisecond},
+		{1046.50, 320 * time.Millisecond},
+	},
+}
+
+// WAV returns the sound of the effect as a WAV file.
+func (e Effect) WAV() []byte {
+	var samples []float64
+	for _, n := range effectNotes[e] {
+		samples = append(samples, Tone(n.freq, n.length, effectVolume)...)
+	}
+	return WAV(samples)
+}
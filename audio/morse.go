@@ -0,0 +1,71 @@
+// audio/morse.go
+package audio
+
+import (
+	"time"
+)
+
+// morseVolume leaves headroom so Morse is not louder than other sounds.
+const morseVolume = 0.6
+
+// MorseTiming sets how fast Morse code is sent.
+type MorseTiming struct {
+	// WPM is the character speed in words per minute
+	WPM int
+
+	// FarnsworthWPM is the overall speed. When it is below WPM, characters
+	// keep their speed and the gaps between them are stretched instead.
+	FarnsworthWPM int
+
+	ToneHz float64
+}
+
+// Dit returns the length of a dot: 1.2 seconds divided by the WPM, from the
+// 50-dot length of the word "PARIS".
+func (t MorseTiming) Dit() time.Duration {
+	return time.Duration(1.2 / float64(max(t.WPM, 1)) * float64(time.Second))
+}
+
+// Gaps returns the silence between the characters of a word and between
+// words. With Farnsworth timing the 19 units of gap in "PARIS " are stretched
+// so the word takes as long as it would at FarnsworthWPM.
+func (t MorseTiming) Gaps() (char, word time.Duration) {
+	dit := t.Dit()
+	if t.FarnsworthWPM <= 0 || t.FarnsworthWPM >= t.WPM {
+		return 3 * dit, 7 * dit
+	}
+
+	c, s := float64(t.WPM), float64(t.FarnsworthWPM)
+	delay := (60*c - 37.2*s) / (s * c) // seconds of gap per word
+	unit := time.Duration(delay / 19 * float64(time.Second))
+	return 3 * unit, 7 * unit
+}
+
+// Morse returns the audio of Morse code written with dots and dashes, spaces
+// between characters and " / " between words, as from data.EncodeMorse.
+func Morse(code string, t MorseTiming) []float64 {
+	dit := t.Dit()
+	charGap, wordGap := t.Gaps()
+
+	var out []float64
+	pending := time.Duration(0) // silence before the next element
+	for _, r := range code {
+		switch r {
+		case '.', '-':
+			length := dit
+			if r == '-' {
+				length = 3 * dit
+			}
+			out = append(out, Silence(pending)...)
+			out = append(out, Tone(t.ToneHz, length, morseVolume)...)
+			pending = dit
+		case ' ':
+			pending = max(pending, charGap)
+		case '/':
+			pending = wordGap
+		}
+	}
+
+	// A short tail so players do not cut off the last element
+	return append(out, Silence(wordGap)...)
+}
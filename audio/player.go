@@ -0,0 +1,125 @@
+// audio/player.go
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Player plays WAV audio, returning once it has finished.
+type Player interface {
+	Play(wav []byte) error
+}
+
+// ErrNoPlayer is returned when no command-line audio player is installed.
+var ErrNoPlayer = errors.New("no audio player found (install paplay, aplay, sox or ffplay)")
+
+// playerCommands are the command-line players tried by NewCommandPlayer, each
+// with the arguments to read a WAV file from standard input.
+var playerCommands = [][]string{
+	{"paplay"},
+	{"aplay", "-q"},
+	{"play", "-q", "-t", "wav", "-"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", "-"},
+}
+
+// NewPlayer returns a player for command, a command line that reads WAV
+// audio from standard input such as "aplay -q". An empty command picks the
+// first player command found. When the command is not installed, audio is
+// discarded by Null.
+func NewPlayer(command string) Player {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		if _, err := exec.LookPath(fields[0]); err != nil {
+			return Null{}
+		}
+		return &CommandPlayer{Command: fields}
+	}
+	if p, err := NewCommandPlayer(); err == nil {
+		return p
+	}
+	return Null{}
+}
+
+// CommandPlayer pipes WAV audio to a command-line player. Playing a sound
+// stops the one still playing, so skipping through cards does not overlap
+// their audio.
+type CommandPlayer struct {
+	Command []string
+
+	mu      sync.Mutex
+	playing *exec.Cmd
+}
+
+// NewCommandPlayer returns a player using the first player command found on
+// the PATH, or ErrNoPlayer.
+func NewCommandPlayer() (*CommandPlayer, error) {
+	for _, command := range playerCommands {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return &CommandPlayer{Command: command}, nil
+		}
+	}
+	return nil, ErrNoPlayer
+}
+
+func (p *CommandPlayer) Play(wav []byte) error {
+	cmd := exec.Command(p.Command[0], p.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(wav)
+
+	p.mu.Lock()
+	if p.playing != nil && p.playing.Process != nil {
+		p.playing.Process.Kill()
+	}
+	if err := cmd.Start(); err != nil {
+		p.mu.Unlock()
+		return fmt.Errorf("failed to start %s: %w", p.Command[0], err)
+	}
+	p.playing = cmd
+	p.mu.Unlock()
+
+	err := cmd.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.playing != cmd {
+		// Stopped by a newer sound
+		return nil
+	}
+	p.playing = nil
+	if err != nil {
+		return fmt.Errorf("failed to play audio with %s: %w", p.Command[0], err)
+	}
+	return nil
+}
+
+// Null discards audio. It stands in when no player is installed.
+type Null struct{}
+
+func (Null) Play(wav []byte) error {
+	return nil
+}
+
+// Recorder keeps the audio it is given instead of playing it, so sounds can
+// be checked without speakers, e.g. in tests or headless runs. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	played [][]byte
+}
+
+func (r *Recorder) Play(wav []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.played = append(r.played, wav)
+	return nil
+}
+
+// Played returns the audio played so far, oldest first.
+func (r *Recorder) Played() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]byte(nil), r.played...)
+}
@@ -0,0 +1,81 @@
+// audio/wav.go
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// SampleRate is the number of samples per second of the generated audio.
+const SampleRate = 22050
+
+// rampDuration fades tones in and out so they start and stop without a click.
+const rampDuration = 5 * time.Millisecond
+
+// samples returns the number of samples that last d.
+func samples(d time.Duration) int {
+	return int(d.Seconds() * SampleRate)
+}
+
+// Tone returns a sine wave of the given frequency and volume between 0 and 1.
+func Tone(freq float64, d time.Duration, volume float64) []float64 {
+	n := samples(d)
+	ramp := min(samples(rampDuration), n/2)
+
+	out := make([]float64, n)
+	for i := range out {
+		gain := volume
+		if edge := min(i, n-1-i); edge < ramp {
+			// Raised cosine fade
+			gain *= 0.5 - 0.5*math.Cos(math.Pi*float64(edge)/float64(ramp))
+		}
+		out[i] = gain * math.Sin(2*math.Pi*freq*float64(i)/SampleRate)
+	}
+	return out
+}
+
+// Silence returns d of silence.
+func Silence(d time.Duration) []float64 {
+	return make([]float64, samples(d))
+}
+
+// WAV encodes samples between -1 and 1 as a mono 16-bit PCM WAV file.
+func WAV(samples []float64) []byte {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		blockAlign    = channels * bitsPerSample / 8
+	)
+	dataSize := len(samples) * blockAlign
+
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+	write := func(v any) {
+		// Writes to a bytes.Buffer cannot fail
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+
+	buf.WriteString("RIFF")
+	write(uint32(36 + dataSize))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	write(uint32(16)) // size of the format chunk
+	write(uint16(1))  // PCM
+	write(uint16(channels))
+	write(uint32(SampleRate))
+	write(uint32(SampleRate * blockAlign)) // bytes per second
+	write(uint16(blockAlign))
+	write(uint16(bitsPerSample))
+
+	buf.WriteString("data")
+	write(uint32(dataSize))
+	for _, s := range samples {
+		s = max(-1, min(1, s))
+		write(int16(s * math.MaxInt16))
+	}
+
+	return buf.Bytes()
+}
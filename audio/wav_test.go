@@ -0,0 +1,135 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// checkHeader checks the 44-byte header of a mono 16-bit PCM WAV file and
+// returns its samples.
+func checkHeader(t *testing.T, wav []byte) []int16 {
+	t.Helper()
+
+	if len(wav) < 44 {
+		t.Fatalf("WAV is %d bytes, shorter than its header", len(wav))
+	}
+	le := binary.LittleEndian
+	dataSize := len(wav) - 44
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"RIFF id", string(wav[0:4]), "RIFF"},
+		{"RIFF size", le.Uint32(wav[4:8]), uint32(36 + dataSize)},
+		{"format", string(wav[8:12]), "WAVE"},
+		{"fmt id", string(wav[12:16]), "fmt "},
+		{"fmt size", le.Uint32(wav[16:20]), uint32(16)},
+		{"encoding", le.Uint16(wav[20:22]), uint16(1)},
+		{"channels", le.Uint16(wav[22:24]), uint16(1)},
+		{"sample rate", le.Uint32(wav[24:28]), uint32(SampleRate)},
+		{"byte rate", le.Uint32(wav[28:32]), uint32(SampleRate * 2)},
+		{"block align", le.Uint16(wav[32:34]), uint16(2)},
+		{"bits per sample", le.Uint16(wav[34:36]), uint16(16)},
+		{"data id", string(wav[36:40]), "data"},
+		{"data size", le.Uint32(wav[40:44]), uint32(dataSize)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	samples := make([]int16, dataSize/2)
+	for i := range samples {
+		samples[i] = int16(le.Uint16(wav[44+2*i:]))
+	}
+	return samples
+}
+
+func TestWAV(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		want    []int16
+	}{
+		{"empty", nil, []int16{}},
+		{"silence", []float64{0, 0}, []int16{0, 0}},
+		{"full scale", []float64{1, -1, 0.5}, []int16{math.MaxInt16, -math.MaxInt16, math.MaxInt16 / 2}},
+		{"clipped", []float64{2, -3}, []int16{math.MaxInt16, -math.MaxInt16}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkHeader(t, WAV(tt.samples))
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d samples, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sample %d = %d, want %d", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEffectWAV(t *testing.T) {
+	tests := []struct {
+		name   string
+		effect Effect
+		length time.Duration
+	}{
+		{"correct", EffectCorrect, 210 * time.Millisecond},
+		{"wrong", EffectWrong, 360 * time.Millisecond},
+		{"complete", EffectComplete, 620 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkHeader(t, tt.effect.WAV())
+
+			// Each note is rounded down to whole samples
+			notes := len(effectNotes[tt.effect])
+			if want := samples(tt.length); len(got) > want || len(got) < want-notes {
+				t.Errorf("%d samples, want about %d", len(got), want)
+			}
+
+			peak := 0
+			for _, s := range got {
+				peak = max(peak, int(s), -int(s))
+			}
+			if limit := int(math.Ceil(effectVolume * math.MaxInt16)); peak == 0 || peak > limit {
+				t.Errorf("peak = %d, want between 1 and %d", peak, limit)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var r Recorder
+	sounds := [][]byte{EffectCorrect.WAV(), EffectWrong.WAV()}
+	for _, wav := range sounds {
+		if err := r.Play(wav); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	played := r.Played()
+	if len(played) != len(sounds) {
+		t.Fatalf("played %d sounds, want %d", len(played), len(sounds))
+	}
+	for i := range sounds {
+		if string(played[i]) != string(sounds[i]) {
+			t.Errorf("sound %d differs from what was played", i)
+		}
+	}
+
+	played[0] = nil
+	if r.Played()[0] == nil {
+		t.Error("Played returned the recorder's own slice")
+	}
+}
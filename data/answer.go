@@ -0,0 +1,186 @@
+// data/answer.go
+package data
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CloseAnswerRatio is the minimum similarity for a typed answer that is not
+// exact to still count as close.
+const CloseAnswerRatio = 0.8
+
+// AnswerNormalization controls which differences are ignored when comparing a
+// typed answer to the expected one.
+type AnswerNormalization struct {
+	IgnoreCase        bool `yaml:"ignore_case"`
+	IgnoreWhitespace  bool `yaml:"ignore_whitespace"`
+	IgnoreAccents     bool `yaml:"ignore_accents"`
+	IgnorePunctuation bool `yaml:"ignore_punctuation"`
+}
+
+func DefaultAnswerNormalization() AnswerNormalization {
+	return AnswerNormalization{
+		IgnoreCase:        true,
+		IgnoreWhitespace:  true,
+		IgnoreAccents:     false,
+		IgnorePunctuation: true,
+	}
+}
+
+// DiffOp says how a segment of a typed answer relates to the expected answer.
+type DiffOp int
+
+const (
+	DiffEqual   DiffOp = iota // typed and expected
+	DiffMissing               // expected but not typed
+	DiffExtra                 // typed but not expected
+)
+
+// DiffSegment is a run of characters with the same DiffOp.
+type DiffSegment struct {
+	Op   DiffOp
+	Text string
+}
+
+// AnswerResult is the outcome of checking a typed answer.
+type AnswerResult struct {
+	Diff       []DiffSegment
+	Similarity float64 // 0 to 1
+	Exact      bool
+}
+
+// Grade maps the result to a grade: exact answers are good, close ones hard
+// and anything else again.
+func (r AnswerResult) Grade() Grade {
+	switch {
+	case r.Exact:
+		return GradeGood
+	case r.Similarity >= CloseAnswerRatio:
+		return GradeHard
+	default:
+		return GradeAgain
+	}
+}
+
+// symbol is a character of an answer together with the key it is compared by.
+type symbol struct {
+	display rune
+	key     rune
+}
+
+// CheckAnswer compares a typed answer to the expected one character by
+// character, after applying the normalisation rules.
+func CheckAnswer(typed, expected string, n AnswerNormalization) AnswerResult {
+	a := symbols(typed, n)
+	b := symbols(expected, n)
+
+	// Longest common subsequence table over the comparison keys
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].key == b[j].key {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result AnswerResult
+	add := func(op DiffOp, r rune) {
+		if last := len(result.Diff) - 1; last >= 0 && result.Diff[last].Op == op {
+			result.Diff[last].Text += string(r)
+			return
+		}
+		result.Diff = append(result.Diff, DiffSegment{Op: op, Text: string(r)})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].key == b[j].key:
+			add(DiffEqual, b[j].display)
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffExtra, a[i].display)
+			i++
+		default:
+			add(DiffMissing, b[j].display)
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(DiffExtra, a[i].display)
+	}
+	for ; j < len(b); j++ {
+		add(DiffMissing, b[j].display)
+	}
+
+	common := lcs[0][0]
+	result.Exact = len(a) == len(b) && common == len(a)
+	if total := len(a) + len(b); total > 0 {
+		result.Similarity = 2 * float64(common) / float64(total)
+	}
+
+	return result
+}
+
+// symbols splits an answer into comparable characters. Ignored punctuation is
+// dropped and ignored whitespace is trimmed and collapsed to single spaces.
+func symbols(s string, n AnswerNormalization) []symbol {
+	var out []symbol
+
+	if n.IgnoreWhitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+
+	for _, r := range s {
+		if n.IgnorePunctuation && unicode.IsPunct(r) {
+			continue
+		}
+
+		key := r
+		if n.IgnoreAccents {
+			key = stripAccent(key)
+		}
+		if n.IgnoreCase {
+			key = unicode.ToLower(key)
+		}
+
+		out = append(out, symbol{display: r, key: key})
+	}
+
+	if n.IgnorePunctuation && n.IgnoreWhitespace {
+		// Dropping punctuation can leave doubled or trailing spaces
+		var collapsed []symbol
+		for _, sym := range out {
+			if sym.key == ' ' && (len(collapsed) == 0 || collapsed[len(collapsed)-1].key == ' ') {
+				continue
+			}
+			collapsed = append(collapsed, sym)
+		}
+		if len(collapsed) > 0 && collapsed[len(collapsed)-1].key == ' ' {
+			collapsed = collapsed[:len(collapsed)-1]
+		}
+		out = collapsed
+	}
+
+	return out
+}
+
+// stripAccent returns the base letter of an accented character, e.g. é -> e.
+func stripAccent(r rune) rune {
+	for _, base := range norm.NFD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, base) {
+			return base
+		}
+	}
+	return r
+}
@@ -0,0 +1,134 @@
+package data
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCheckAnswerDiff(t *testing.T) {
+	exact := AnswerNormalization{}
+
+	tests := []struct {
+		name     string
+		typed    string
+		expected string
+		diff     []DiffSegment
+		exact    bool
+	}{
+		{
+			name:     "identical",
+			typed:    "goroutine",
+			expected: "goroutine",
+			diff:     []DiffSegment{{DiffEqual, "goroutine"}},
+			exact:    true,
+		},
+		{
+			name:     "missing letter",
+			typed:    "gorutine",
+			expected: "goroutine",
+			diff: []DiffSegment{
+				{DiffEqual, "gor"},
+				{DiffMissing, "o"},
+				{DiffEqual, "utine"},
+			},
+		},
+		{
+			name:     "extra letter",
+			typed:    "deferr",
+			expected: "defer",
+			diff: []DiffSegment{
+				{DiffEqual, "defer"},
+				{DiffExtra, "r"},
+			},
+		},
+		{
+			name:     "wrong letter",
+			typed:    "cat",
+			expected: "cut",
+			diff: []DiffSegment{
+				{DiffEqual, "c"},
+				{DiffExtra, "a"},
+				{DiffMissing, "u"},
+				{DiffEqual, "t"},
+			},
+		},
+		{
+			name:     "nothing typed",
+			typed:    "",
+			expected: "go",
+			diff:     []DiffSegment{{DiffMissing, "go"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckAnswer(tt.typed, tt.expected, exact)
+			if !reflect.DeepEqual(got.Diff, tt.diff) {
+				t.Errorf("diff = %v, want %v", got.Diff, tt.diff)
+			}
+			if got.Exact != tt.exact {
+				t.Errorf("exact = %v, want %v", got.Exact, tt.exact)
+			}
+		})
+	}
+}
+
+func TestCheckAnswerNormalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		typed    string
+		expected string
+		n        AnswerNormalization
+		exact    bool
+	}{
+		{"case ignored", "GoRoutine", "goroutine", AnswerNormalization{IgnoreCase: true}, true},
+		{"case kept", "GoRoutine", "goroutine", AnswerNormalization{}, false},
+		{"whitespace collapsed", "  hello \t world ", "hello world", AnswerNormalization{IgnoreWhitespace: true}, true},
+		{"whitespace kept", "hello  world", "hello world", AnswerNormalization{}, false},
+		{"accents ignored", "cafe", "café", AnswerNormalization{IgnoreAccents: true}, true},
+		{"accents kept", "cafe", "café", AnswerNormalization{}, false},
+		{"punctuation ignored", "hello world", "hello, world!", AnswerNormalization{IgnorePunctuation: true}, true},
+		{"punctuation leaves no double spaces", "a b", "a - b", AnswerNormalization{IgnorePunctuation: true, IgnoreWhitespace: true}, true},
+		{"defaults", "Hello World", "hello, world.", DefaultAnswerNormalization(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckAnswer(tt.typed, tt.expected, tt.n)
+			if got.Exact != tt.exact {
+				t.Errorf("CheckAnswer(%q, %q).Exact = %v, want %v (diff %v)",
+					tt.typed, tt.expected, got.Exact, tt.exact, got.Diff)
+			}
+		})
+	}
+}
+
+func TestCheckAnswerGrade(t *testing.T) {
+	n := DefaultAnswerNormalization()
+
+	tests := []struct {
+		name       string
+		typed      string
+		expected   string
+		similarity float64
+		grade      Grade
+	}{
+		{"exact is good", "defer", "defer", 1, GradeGood},
+		{"close is hard", "goroutin", "goroutine", 16.0 / 17, GradeHard},
+		{"far off is again", "channel", "goroutine", 2 * 2.0 / 16, GradeAgain},
+		{"empty is again", "", "defer", 0, GradeAgain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckAnswer(tt.typed, tt.expected, n)
+			if math.Abs(got.Similarity-tt.similarity) > 1e-9 {
+				t.Errorf("similarity = %v, want %v", got.Similarity, tt.similarity)
+			}
+			if grade := got.Grade(); grade != tt.grade {
+				t.Errorf("grade = %v, want %v", grade, tt.grade)
+			}
+		})
+	}
+}
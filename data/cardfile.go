@@ -0,0 +1,97 @@
+// data/cardfile.go
+package data
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CardFile is the editable form of a card, written to a temporary YAML file
+// so it can be edited in an external editor.
+type CardFile struct {
+	Question string   `yaml:"question"`
+	Answer   string   `yaml:"answer"`
+	Tags     []string `yaml:"tags,flow"`
+}
+
+const cardFileHeader = `# Edit the card, then save and quit the editor.
+# Use "|" for multi-line text and {{c1::...}} for cloze deletions.
+
+`
+
+// NewCardFile returns the editable form of a card.
+func NewCardFile(card Card) CardFile {
+	return CardFile{
+		Question: card.Question,
+		Answer:   card.Answer,
+		Tags:     append([]string{}, card.Tags...),
+	}
+}
+
+// MarshalCardFile renders a card file with a short explanatory header.
+func MarshalCardFile(f CardFile) ([]byte, error) {
+	if f.Tags == nil {
+		f.Tags = []string{}
+	}
+
+	body, err := yaml.Marshal(&f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal card: %w", err)
+	}
+
+	return append([]byte(cardFileHeader), body...), nil
+}
+
+// ParseCardFile reads an edited card file back and validates it. Unknown
+// fields are rejected so that typos are not silently dropped.
+func ParseCardFile(b []byte) (CardFile, error) {
+	var f CardFile
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		if errors.Is(err, io.EOF) {
+			return CardFile{}, errors.New("card file is empty")
+		}
+		return CardFile{}, fmt.Errorf("failed to parse card file: %w", err)
+	}
+
+	f.Question = strings.TrimSpace(f.Question)
+	f.Answer = strings.TrimSpace(f.Answer)
+
+	var tags []string
+	for _, tag := range f.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	f.Tags = tags
+
+	if err := f.Validate(); err != nil {
+		return CardFile{}, err
+	}
+
+	return f, nil
+}
+
+// Validate checks that the card has a question, and an answer unless the
+// question contains cloze deletions.
+func (f CardFile) Validate() error {
+	if f.Question == "" {
+		return errors.New("question cannot be empty")
+	}
+	if f.Answer == "" && !HasCloze(f.Question) {
+		return errors.New("answer cannot be empty unless the question has cloze deletions")
+	}
+	return nil
+}
+
+// Card returns a new card with the file's contents.
+func (f CardFile) Card() Card {
+	return NewCard(f.Question, f.Answer, f.Tags)
+}
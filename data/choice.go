@@ -0,0 +1,71 @@
+// data/choice.go
+package data
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// ChoiceCount is the number of options shown for a multiple-choice card.
+const ChoiceCount = 4
+
+// IsMultipleChoice reports whether a card of this deck is reviewed by picking
+// from options: either it has its own distractors, or the deck draws them
+// from the answers of its other cards.
+func (d *Deck) IsMultipleChoice(card *Card) bool {
+	if card == nil || card.IsCloze() {
+		return false
+	}
+	return len(card.Distractors) > 0 || d.MultipleChoice
+}
+
+// Choices returns the shuffled options for a multiple-choice card and the
+// index of the correct answer among them. The card's own distractors are used
+// first; remaining slots are filled with answers of other cards in the deck.
+func (d *Deck) Choices(card *Card, rng *rand.Rand) ([]string, int) {
+	seen := map[string]bool{normalizeChoice(card.Answer): true}
+	var wrong []string
+
+	add := func(candidates []string) {
+		rng.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		for _, candidate := range candidates {
+			key := normalizeChoice(candidate)
+			if len(wrong) == ChoiceCount-1 || key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			wrong = append(wrong, candidate)
+		}
+	}
+
+	add(append([]string(nil), card.Distractors...))
+
+	others := make([]string, 0, len(d.Cards))
+	for i := range d.Cards {
+		if d.Cards[i].ID != card.ID && !d.Cards[i].IsCloze() {
+			others = append(others, d.Cards[i].Answer)
+		}
+	}
+	add(others)
+
+	choices := append(wrong, card.Answer)
+	rng.Shuffle(len(choices), func(i, j int) {
+		choices[i], choices[j] = choices[j], choices[i]
+	})
+
+	correct := 0
+	for i, choice := range choices {
+		if choice == card.Answer {
+			correct = i
+			break
+		}
+	}
+
+	return choices, correct
+}
+
+func normalizeChoice(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,100 @@
+// data/cloze.go
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// clozePattern matches {{c1::answer}} and {{c1::answer::hint}}; the answer may
+// span lines.
+var clozePattern = regexp.MustCompile(`(?s)\{\{c(\d+)::(.*?)(?:::(.*?))?\}\}`)
+
+// HasCloze reports whether text contains any cloze deletion markers.
+func HasCloze(text string) bool {
+	return clozePattern.MatchString(text)
+}
+
+// ClozeIndexes returns the distinct cloze numbers used in text, in ascending
+// order. Each number becomes its own review item.
+func ClozeIndexes(text string) []int {
+	seen := make(map[int]bool)
+	var indexes []int
+
+	for _, match := range clozePattern.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || seen[n] {
+			continue
+		}
+		seen[n] = true
+		indexes = append(indexes, n)
+	}
+
+	sort.Ints(indexes)
+	return indexes
+}
+
+// ClozeKey returns the review item key for a cloze number, e.g. "c1".
+func ClozeKey(index int) string {
+	return fmt.Sprintf("c%d", index)
+}
+
+// ClozeIndex parses a review item key produced by ClozeKey. It returns 0 for
+// keys that are not cloze keys.
+func ClozeIndex(key string) int {
+	if !strings.HasPrefix(key, "c") {
+		return 0
+	}
+	n, err := strconv.Atoi(key[1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// ClozeFront renders text with cloze number index blanked out as "[...]", or
+// "[hint]" if the marker has a hint. Other clozes are shown filled in.
+func ClozeFront(text string, index int) string {
+	return clozePattern.ReplaceAllStringFunc(text, func(marker string) string {
+		match := clozePattern.FindStringSubmatch(marker)
+		if n, _ := strconv.Atoi(match[1]); n != index {
+			return match[2]
+		}
+		if match[3] != "" {
+			return "[" + match[3] + "]"
+		}
+		return "[...]"
+	})
+}
+
+// ClozeBack renders text with every cloze filled in, passing the fill of
+// cloze number index through highlight.
+func ClozeBack(text string, index int, highlight func(string) string) string {
+	return clozePattern.ReplaceAllStringFunc(text, func(marker string) string {
+		match := clozePattern.FindStringSubmatch(marker)
+		if n, _ := strconv.Atoi(match[1]); n == index {
+			return highlight(match[2])
+		}
+		return match[2]
+	})
+}
+
+// ClozeText renders text with every cloze filled in and nothing highlighted.
+func ClozeText(text string) string {
+	return clozePattern.ReplaceAllString(text, "$2")
+}
+
+// ClozeAnswer returns the text hidden by cloze number index, joining several
+// occurrences with ", ".
+func ClozeAnswer(text string, index int) string {
+	var fills []string
+	for _, match := range clozePattern.FindAllStringSubmatch(text, -1) {
+		if n, _ := strconv.Atoi(match[1]); n == index {
+			fills = append(fills, match[2])
+		}
+	}
+	return strings.Join(fills, ", ")
+}
@@ -2,13 +2,50 @@
 package data
 
 import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// Card types. Basic cards have an empty type.
+const (
+	CardTypeBasic = ""
+	CardTypeCloze = "cloze"
+	CardTypeMorse = "morse"
+)
+
+// ReverseKey is the review item key of the answer→question direction of a
+// bidirectional card.
+const ReverseKey = "reverse"
+
 type Card struct {
-	Question string   `yaml:"question"`
-	Answer   string   `yaml:"answer"`
-	Tags     []string `yaml:"tags"`
+	ID       uuid.UUID   `yaml:"id"`
+	Type     string      `yaml:"type,omitempty"`
+	Question string      `yaml:"question"`
+	Answer   string      `yaml:"answer"`
+	Tags     []string    `yaml:"tags"`
+	Review   ReviewState `yaml:"review,omitempty"`
+
+	// Distractors are wrong answers offered when reviewing as multiple choice.
+	Distractors []string `yaml:"distractors,omitempty"`
+
+	// Reverse also reviews the card from answer to question.
+	Reverse bool `yaml:"reverse,omitempty"`
+
+	// Items holds the review state of every item except the default one,
+	// e.g. each cloze deletion of a cloze card or the reverse direction.
+	Items map[string]ReviewState `yaml:"items,omitempty"`
+}
+
+// ReviewItem is a single reviewable unit of a deck. Most cards produce one
+// item with an empty key; cloze cards produce one item per cloze number and
+// bidirectional cards add a ReverseKey item.
+type ReviewItem struct {
+	CardIndex int
+	Key       string
 }
 
 type Deck struct {
@@ -16,29 +53,185 @@ type Deck struct {
 	Name      string    `yaml:"name"`
 	Cards     []Card    `yaml:"cards"`
 	CurrentID int       `yaml:"current_id"`
+
+	// Metadata
+	Description string    `yaml:"description,omitempty"`
+	Author      string    `yaml:"author,omitempty"`
+	Color       string    `yaml:"color,omitempty"` // hex "#RRGGBB" or ANSI 0-255
+	Emoji       string    `yaml:"emoji,omitempty"`
+	Created     time.Time `yaml:"created,omitempty"`
+	Updated     time.Time `yaml:"updated,omitempty"`
+
+	// CurrentItem is the key of the current review item of the current card.
+	CurrentItem string `yaml:"current_item,omitempty"`
+
+	// Reverse makes every basic card bidirectional.
+	Reverse bool `yaml:"reverse,omitempty"`
+
+	// MultipleChoice reviews every basic card by picking from options.
+	MultipleChoice bool `yaml:"multiple_choice,omitempty"`
+
+	// Morse reviews every basic card as Morse code.
+	Morse bool `yaml:"morse,omitempty"`
+
+	// PlainText shows card faces as typed instead of rendering Markdown.
+	PlainText bool `yaml:"plain_text,omitempty"`
+
+	// Shuffle studies the deck in a random order by default.
+	Shuffle bool `yaml:"shuffle,omitempty"`
+
+	// ShuffleSeed fixes the random order of the current session; zero keeps
+	// card order. It is stored so a resumed session keeps its order.
+	ShuffleSeed int64 `yaml:"shuffle_seed,omitempty"`
+
+	// Scheduler overrides the default algorithm from Settings.
+	Scheduler string      `yaml:"scheduler,omitempty"`
+	FSRS      *FSRSParams `yaml:"fsrs,omitempty"`
 }
 
+// NewCard creates a card, making it a cloze card when the question contains
+// cloze markers.
 func NewCard(q, a string, tags []string) Card {
+	cardType := CardTypeBasic
+	if HasCloze(q) {
+		cardType = CardTypeCloze
+	}
+
 	return Card{
+		ID:       uuid.New(),
+		Type:     cardType,
 		Question: q,
 		Answer:   a,
 		Tags:     tags,
 	}
 }
 
+func (c *Card) IsCloze() bool {
+	return c.Type == CardTypeCloze
+}
+
+// ItemKeys returns the keys of the review items this card produces on its
+// own. Use Deck.ItemKeys to include deck-wide options.
+func (c *Card) ItemKeys() []string {
+	if c.IsCloze() {
+		indexes := ClozeIndexes(c.Question)
+		if len(indexes) > 0 {
+			keys := make([]string, len(indexes))
+			for i, index := range indexes {
+				keys[i] = ClozeKey(index)
+			}
+			return keys
+		}
+	}
+	if c.Reverse {
+		return []string{"", ReverseKey}
+	}
+	return []string{""}
+}
+
+// State returns the review state of the item with the given key.
+func (c *Card) State(key string) ReviewState {
+	if key == "" {
+		return c.Review
+	}
+	return c.Items[key]
+}
+
+// SetState stores the review state of the item with the given key.
+func (c *Card) SetState(key string, s ReviewState) {
+	if key == "" {
+		c.Review = s
+		return
+	}
+	if c.Items == nil {
+		c.Items = make(map[string]ReviewState)
+	}
+	c.Items[key] = s
+}
+
+// Lapses returns the total number of lapses over all items of the card.
+func (c *Card) Lapses() int {
+	lapses := c.Review.Lapses
+	for _, state := range c.Items {
+		lapses += state.Lapses
+	}
+	return lapses
+}
+
+// ItemKeys returns the keys of the review items a card produces in this deck.
+func (d *Deck) ItemKeys(card *Card) []string {
+	keys := card.ItemKeys()
+	if d.Reverse && !card.IsCloze() && !card.Reverse {
+		keys = append(keys, ReverseKey)
+	}
+	return keys
+}
+
 func NewDeck(name string) *Deck {
+	now := time.Now()
 	return &Deck{
 		ID:        uuid.New(),
 		Name:      name,
 		Cards:     []Card{},
 		CurrentID: 0,
+		Created:   now,
+		Updated:   now,
 	}
 }
 
+// Touch marks the deck as modified.
+func (d *Deck) Touch() {
+	d.Updated = time.Now()
+}
+
 func (d *Deck) AddCard(card Card) {
+	if card.ID == uuid.Nil {
+		card.ID = uuid.New()
+	}
 	d.Cards = append(d.Cards, card)
 }
 
+// EnsureCardIDs assigns an ID to every card that lacks one, e.g. cards from
+// deck files written before cards had IDs. It reports whether any card changed.
+func (d *Deck) EnsureCardIDs() bool {
+	changed := false
+	for i := range d.Cards {
+		if d.Cards[i].ID == uuid.Nil {
+			d.Cards[i].ID = uuid.New()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// CardIndex returns the position of the card with the given ID, or -1.
+func (d *Deck) CardIndex(id uuid.UUID) int {
+	for i, card := range d.Cards {
+		if card.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetCard returns the card with the given ID, or nil.
+func (d *Deck) GetCard(id uuid.UUID) *Card {
+	if i := d.CardIndex(id); i >= 0 {
+		return &d.Cards[i]
+	}
+	return nil
+}
+
+// SelectCard makes the card with the given ID the current card.
+func (d *Deck) SelectCard(id uuid.UUID) bool {
+	i := d.CardIndex(id)
+	if i < 0 {
+		return false
+	}
+	d.CurrentID = i
+	return true
+}
+
 func (d *Deck) RemoveCard(index int) {
 	if index < 0 || index >= len(d.Cards) {
 		return
@@ -54,23 +247,279 @@ func (d *Deck) RemoveCard(index int) {
 	}
 }
 
+// Items returns every review item of the deck in card order.
+func (d *Deck) Items() []ReviewItem {
+	items := make([]ReviewItem, 0, len(d.Cards))
+	for i := range d.Cards {
+		for _, key := range d.ItemKeys(&d.Cards[i]) {
+			items = append(items, ReviewItem{CardIndex: i, Key: key})
+		}
+	}
+	return items
+}
+
+// StudyOrder returns the review items in the order they are studied: card
+// order, or a permutation fixed by ShuffleSeed. Items keep their relative
+// order in a permutation when cards are added or removed.
+func (d *Deck) StudyOrder() []ReviewItem {
+	items := d.Items()
+	if d.ShuffleSeed == 0 {
+		return items
+	}
+
+	ranks := make(map[ReviewItem]uint64, len(items))
+	for _, item := range items {
+		ranks[item] = shuffleRank(d.ShuffleSeed, d.Cards[item.CardIndex].ID, item.Key)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return ranks[items[i]] < ranks[items[j]]
+	})
+	return items
+}
+
+// shuffleRank places a review item in the permutation of a seed.
+func shuffleRank(seed int64, cardID uuid.UUID, key string) uint64 {
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, seed)
+	h.Write(cardID[:])
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Reshuffle orders the session by the permutation of a new seed and moves to
+// its first due item. A zero seed goes back to card order.
+func (d *Deck) Reshuffle(seed int64, now time.Time) {
+	d.ShuffleSeed = seed
+	if items := d.StudyOrder(); len(items) > 0 {
+		d.CurrentID = items[0].CardIndex
+		d.CurrentItem = items[0].Key
+	}
+	d.SeekDue(now)
+}
+
+// StudyPosition returns the index of the current item in StudyOrder, or -1
+// if there is none, and the number of items.
+func (d *Deck) StudyPosition() (int, int) {
+	items := d.StudyOrder()
+	current := ReviewItem{CardIndex: d.CurrentID, Key: d.CurrentItemKey()}
+	for i, item := range items {
+		if item == current {
+			return i, len(items)
+		}
+	}
+	return -1, len(items)
+}
+
+// CurrentItemKey returns the key of the current review item. It falls back to
+// the first item of the current card if CurrentItem does not belong to it.
+func (d *Deck) CurrentItemKey() string {
+	card := d.CurrentCard()
+	if card == nil {
+		return ""
+	}
+
+	keys := d.ItemKeys(card)
+	for _, key := range keys {
+		if key == d.CurrentItem {
+			return key
+		}
+	}
+	return keys[0]
+}
+
+// CurrentState returns the review state of the current item.
+func (d *Deck) CurrentState() ReviewState {
+	card := d.CurrentCard()
+	if card == nil {
+		return ReviewState{}
+	}
+	return card.State(d.CurrentItemKey())
+}
+
+// NextCard advances to the next card that is due, wrapping around the deck.
 func (d *Deck) NextCard() error {
-	if len(d.Cards) == 0 {
+	if !d.stepDue(1, time.Now()) {
 		return nil
 	}
-
-	d.CurrentID = (d.CurrentID + 1) % len(d.Cards)
 	return SaveDeck(*d)
 }
 
+// PrevCard moves back to the previous card that is due.
 func (d *Deck) PrevCard() error {
-	if len(d.Cards) == 0 {
+	if !d.stepDue(-1, time.Now()) {
 		return nil
 	}
-
-	d.CurrentID = (d.CurrentID - 1 + len(d.Cards)) % len(d.Cards)
 	return SaveDeck(*d)
+}
+
+// stepDue moves the current item in the given direction until it lands on a
+// due item. It reports whether any due item was found.
+func (d *Deck) stepDue(dir int, now time.Time) bool {
+	items := d.StudyOrder()
+	n := len(items)
+	if n == 0 {
+		return false
+	}
+
+	pos := 0
+	current := d.CurrentItemKey()
+	for i, item := range items {
+		if item.CardIndex == d.CurrentID && item.Key == current {
+			pos = i
+			break
+		}
+	}
+
+	for i := 1; i <= n; i++ {
+		item := items[((pos+dir*i)%n+n)%n]
+		if d.Cards[item.CardIndex].State(item.Key).IsDue(now) {
+			d.CurrentID = item.CardIndex
+			d.CurrentItem = item.Key
+			return true
+		}
+	}
+	return false
+}
+
+// SeekDue positions the deck on the first due item, starting from the
+// current one. It reports whether the deck has any due items.
+func (d *Deck) SeekDue(now time.Time) bool {
+	if d.CurrentCard() != nil && d.CurrentState().IsDue(now) {
+		d.CurrentItem = d.CurrentItemKey()
+		return true
+	}
+	return d.stepDue(1, now)
+}
+
+// DueCount returns the number of review items due at the given time.
+func (d *Deck) DueCount(now time.Time) int {
+	count := 0
+	for _, item := range d.Items() {
+		if d.Cards[item.CardIndex].State(item.Key).IsDue(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// NextDue returns the earliest due date among items that are not yet due.
+func (d *Deck) NextDue(now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, item := range d.Items() {
+		due := d.Cards[item.CardIndex].State(item.Key).Due
+		if !due.After(now) {
+			continue
+		}
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// LastStudied returns the time of the most recent review in the deck, or
+// zero if it has never been studied.
+func (d *Deck) LastStudied() time.Time {
+	var last time.Time
+	for _, item := range d.Items() {
+		if reviewed := d.Cards[item.CardIndex].State(item.Key).LastReview; reviewed.After(last) {
+			last = reviewed
+		}
+	}
+	return last
+}
+
+// CardDue returns the earliest due date among the review items of a card.
+// It is zero if any item is new.
+func (d *Deck) CardDue(card *Card) time.Time {
+	var due time.Time
+	for i, key := range d.ItemKeys(card) {
+		if s := card.State(key); i == 0 || s.Due.Before(due) {
+			due = s.Due
+		}
+	}
+	return due
+}
+
+// SchedulerFor returns the scheduler used by this deck, or the one named by
+// fallback when the deck does not choose one. FSRS parameters are filled in on
+// the deck so they are persisted with it.
+func (d *Deck) SchedulerFor(fallback string) Scheduler {
+	name := d.Scheduler
+	if name == "" {
+		name = fallback
+	}
+
+	if name == SchedulerFSRS && d.FSRS == nil {
+		params := DefaultFSRSParams()
+		d.FSRS = &params
+	}
+
+	return NewScheduler(name, d.FSRS)
+}
+
+// GradeCurrent schedules the current card with the given grade, records the
+// review in the review log and moves on to the next due card.
+func (d *Deck) GradeCurrent(s Scheduler, grade Grade, now time.Time, answerTime time.Duration) error {
+	card := d.CurrentCard()
+	if card == nil {
+		return nil
+	}
+
+	// A review log failure must not lose the grade, so the deck is saved
+	// first
+	logErr := d.gradeItem(s, card, d.CurrentItemKey(), grade, now, answerTime)
+
+	d.stepDue(1, now)
+	if err := SaveDeck(*d); err != nil {
+		return err
+	}
+	return logErr
+}
+
+// GradeItem schedules a review item of a card with the given grade and
+// records the review, leaving the current card as it is. Sessions that span
+// decks grade through it.
+func (d *Deck) GradeItem(s Scheduler, card *Card, key string, grade Grade, now time.Time, answerTime time.Duration) error {
+	logErr := d.gradeItem(s, card, key, grade, now, answerTime)
+	if err := SaveDeck(*d); err != nil {
+		return err
+	}
+	return logErr
+}
+
+// gradeItem applies the new review state of the item, then records the
+// review. The state is applied even when the review log cannot be written,
+// which is the error it returns.
+func (d *Deck) gradeItem(s Scheduler, card *Card, key string, grade Grade, now time.Time, answerTime time.Duration) error {
+	before := card.State(key)
+	after := s.Schedule(before, grade, now)
+	card.SetState(key, after)
+
+	return AppendReview(ReviewEntry{
+		Time:      now,
+		Kind:      ReviewKindGrade,
+		DeckID:    d.ID,
+		CardID:    card.ID,
+		Item:      key,
+		Grade:     grade,
+		Scheduler: s.Name(),
+		AnswerMs:  answerTime.Milliseconds(),
+		Before:    &before,
+		After:     &after,
+	})
+}
 
+// RecordItemFlip logs that the answer of a review item of card was revealed.
+func (d *Deck) RecordItemFlip(card *Card, key string, now time.Time, answerTime time.Duration) error {
+	return AppendReview(ReviewEntry{
+		Time:     now,
+		Kind:     ReviewKindFlip,
+		DeckID:   d.ID,
+		CardID:   card.ID,
+		Item:     key,
+		AnswerMs: answerTime.Milliseconds(),
+	})
 }
 
 func (d *Deck) CurrentCard() *Card {
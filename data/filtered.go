@@ -0,0 +1,63 @@
+// data/filtered.go
+package data
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// FilteredDecksFile holds the saved filtered decks, kept next to DecksDir.
+const FilteredDecksFile = "filtered_decks.yaml"
+
+// FilteredDeck is a named query. Studying it reviews the matching items of
+// every deck; the cards stay in their own decks.
+type FilteredDeck struct {
+	ID    uuid.UUID `yaml:"id"`
+	Name  string    `yaml:"name"`
+	Query string    `yaml:"query"`
+}
+
+// Validate checks that the filtered deck has a name and a valid query.
+func (f FilteredDeck) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("filtered deck name cannot be empty")
+	}
+	if _, err := ParseQuery(f.Query); err != nil {
+		return err
+	}
+	return nil
+}
+
+func SaveFilteredDecks(filters []FilteredDeck) error {
+	data, err := yaml.Marshal(filters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filtered decks: %w", err)
+	}
+
+	if err := os.WriteFile(FilteredDecksFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write filtered decks file: %w", err)
+	}
+
+	return nil
+}
+
+func LoadFilteredDecks() ([]FilteredDeck, error) {
+	data, err := os.ReadFile(FilteredDecksFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read filtered decks file: %w", err)
+	}
+
+	var filters []FilteredDeck
+	if err := yaml.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("failed to parse filtered decks file: %w", err)
+	}
+
+	return filters, nil
+}
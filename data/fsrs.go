@@ -0,0 +1,165 @@
+// data/fsrs.go
+package data
+
+import (
+	"math"
+	"time"
+)
+
+// FSRS forgetting curve constants (FSRS-4.5).
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// FSRSParams are the tunable parameters of the FSRS algorithm. They are
+// stored in the deck file so that schedules can be reproduced.
+type FSRSParams struct {
+	Weights          []float64 `yaml:"weights"`
+	DesiredRetention float64   `yaml:"desired_retention"`
+	MaximumInterval  int       `yaml:"maximum_interval"` // days
+}
+
+// DefaultFSRSParams returns the published FSRS-4.5 default weights.
+func DefaultFSRSParams() FSRSParams {
+	return FSRSParams{
+		Weights: []float64{
+			0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+			0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+		},
+		DesiredRetention: 0.9,
+		MaximumInterval:  36500,
+	}
+}
+
+// FSRS implements the Free Spaced Repetition Scheduler. Each card tracks a
+// memory stability and difficulty, and the next interval is chosen so that
+// the predicted retrievability drops to the desired retention on the due date.
+type FSRS struct {
+	Params FSRSParams
+}
+
+func (FSRS) Name() string { return SchedulerFSRS }
+
+// Schedule applies FSRS to a review state and returns the updated state.
+func (f FSRS) Schedule(s ReviewState, grade Grade, now time.Time) ReviewState {
+	w := f.weights()
+
+	if s.Stability == 0 {
+		// First review under FSRS
+		s.Stability = w[int(grade)-1]
+		s.Difficulty = f.initDifficulty(grade)
+	} else {
+		elapsed := now.Sub(s.LastReview).Hours() / 24
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		r := Retrievability(elapsed, s.Stability)
+
+		s.Difficulty = f.nextDifficulty(s.Difficulty, grade)
+		if grade == GradeAgain {
+			s.Stability = f.forgetStability(s.Difficulty, s.Stability, r)
+		} else {
+			s.Stability = f.recallStability(s.Difficulty, s.Stability, r, grade)
+		}
+	}
+
+	if grade == GradeAgain {
+		if s.Repetitions > 0 {
+			s.Lapses++
+		}
+		s.Repetitions = 0
+		s.Interval = 0
+	} else {
+		s.Repetitions++
+		s.Interval = f.nextInterval(s.Stability)
+	}
+
+	s.LastReview = now
+	s.Due = now.AddDate(0, 0, s.Interval)
+
+	return s
+}
+
+// Retrievability is the predicted probability of recalling a card with the
+// given stability after elapsed days.
+func Retrievability(elapsed, stability float64) float64 {
+	if stability <= 0 {
+		return 0
+	}
+	return math.Pow(1+fsrsFactor*elapsed/stability, fsrsDecay)
+}
+
+// weights returns the configured weights, falling back to the defaults when
+// the deck file holds an incomplete set.
+func (f FSRS) weights() []float64 {
+	if len(f.Params.Weights) < 17 {
+		return DefaultFSRSParams().Weights
+	}
+	return f.Params.Weights
+}
+
+func (f FSRS) initDifficulty(grade Grade) float64 {
+	w := f.weights()
+	return clampDifficulty(w[4] - float64(grade-3)*w[5])
+}
+
+func (f FSRS) nextDifficulty(d float64, grade Grade) float64 {
+	w := f.weights()
+	next := d - w[6]*float64(grade-3)
+	// Mean reversion towards the initial difficulty of a "good" answer
+	next = w[7]*f.initDifficulty(GradeGood) + (1-w[7])*next
+	return clampDifficulty(next)
+}
+
+func (f FSRS) recallStability(d, s, r float64, grade Grade) float64 {
+	w := f.weights()
+
+	hardPenalty := 1.0
+	if grade == GradeHard {
+		hardPenalty = w[15]
+	}
+	easyBonus := 1.0
+	if grade == GradeEasy {
+		easyBonus = w[16]
+	}
+
+	return s * (1 + math.Exp(w[8])*
+		(11-d)*
+		math.Pow(s, -w[9])*
+		(math.Exp((1-r)*w[10])-1)*
+		hardPenalty*
+		easyBonus)
+}
+
+func (f FSRS) forgetStability(d, s, r float64) float64 {
+	w := f.weights()
+	return w[11] *
+		math.Pow(d, -w[12]) *
+		(math.Pow(s+1, w[13]) - 1) *
+		math.Exp((1-r)*w[14])
+}
+
+func (f FSRS) nextInterval(stability float64) int {
+	retention := f.Params.DesiredRetention
+	if retention <= 0 || retention >= 1 {
+		retention = DefaultFSRSParams().DesiredRetention
+	}
+	maxInterval := f.Params.MaximumInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultFSRSParams().MaximumInterval
+	}
+
+	interval := int(math.Round(stability / fsrsFactor * (math.Pow(retention, 1/fsrsDecay) - 1)))
+	if interval < 1 {
+		interval = 1
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
@@ -0,0 +1,173 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const fsrsTolerance = 1e-6
+
+func TestFSRSFirstReview(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		grade      Grade
+		stability  float64
+		difficulty float64
+		interval   int
+	}{
+		{GradeAgain, 0.4872, 7.6214, 0},
+		{GradeHard, 1.4003, 6.3916, 1},
+		{GradeGood, 3.7145, 5.1618, 4},
+		{GradeEasy, 13.8206, 3.932, 14},
+	}
+
+	f := FSRS{Params: DefaultFSRSParams()}
+	for _, tt := range tests {
+		t.Run(tt.grade.String(), func(t *testing.T) {
+			got := f.Schedule(ReviewState{}, tt.grade, now)
+
+			if math.Abs(got.Stability-tt.stability) > fsrsTolerance {
+				t.Errorf("stability = %v, want %v", got.Stability, tt.stability)
+			}
+			if math.Abs(got.Difficulty-tt.difficulty) > fsrsTolerance {
+				t.Errorf("difficulty = %v, want %v", got.Difficulty, tt.difficulty)
+			}
+			if got.Interval != tt.interval {
+				t.Errorf("interval = %d, want %d", got.Interval, tt.interval)
+			}
+			if want := now.AddDate(0, 0, tt.interval); !got.Due.Equal(want) {
+				t.Errorf("due = %v, want %v", got.Due, want)
+			}
+			if !got.LastReview.Equal(now) {
+				t.Errorf("last review = %v, want %v", got.LastReview, now)
+			}
+		})
+	}
+}
+
+func TestFSRSLaterReview(t *testing.T) {
+	last := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := last.AddDate(0, 0, 4)
+	before := ReviewState{
+		Stability:   3.7145,
+		Difficulty:  5.1618,
+		Interval:    4,
+		Repetitions: 1,
+		LastReview:  last,
+		Due:         now,
+	}
+
+	tests := []struct {
+		grade       Grade
+		stability   float64
+		difficulty  float64
+		interval    int
+		repetitions int
+		lapses      int
+	}{
+		{GradeAgain, 1.4006056900, 6.901155, 0, 0, 1},
+		{GradeHard, 5.8595091134, 6.0314775, 6, 2, 0},
+		{GradeGood, 14.8081005065, 5.1618, 15, 2, 0},
+		{GradeEasy, 40.3660249216, 4.2921225, 40, 2, 0},
+	}
+
+	f := FSRS{Params: DefaultFSRSParams()}
+	for _, tt := range tests {
+		t.Run(tt.grade.String(), func(t *testing.T) {
+			got := f.Schedule(before, tt.grade, now)
+
+			if math.Abs(got.Stability-tt.stability) > fsrsTolerance {
+				t.Errorf("stability = %v, want %v", got.Stability, tt.stability)
+			}
+			if math.Abs(got.Difficulty-tt.difficulty) > fsrsTolerance {
+				t.Errorf("difficulty = %v, want %v", got.Difficulty, tt.difficulty)
+			}
+			if got.Interval != tt.interval {
+				t.Errorf("interval = %d, want %d", got.Interval, tt.interval)
+			}
+			if got.Repetitions != tt.repetitions {
+				t.Errorf("repetitions = %d, want %d", got.Repetitions, tt.repetitions)
+			}
+			if got.Lapses != tt.lapses {
+				t.Errorf("lapses = %d, want %d", got.Lapses, tt.lapses)
+			}
+		})
+	}
+}
+
+func TestFSRSDifficultyIsClamped(t *testing.T) {
+	f := FSRS{Params: DefaultFSRSParams()}
+
+	tests := []struct {
+		name  string
+		d     float64
+		grade Grade
+		want  float64
+	}{
+		{"again at the top stays at 10", 10, GradeAgain, 10},
+		{"easy at the bottom stays at 1", 1, GradeEasy, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.nextDifficulty(tt.d, tt.grade); got != tt.want {
+				t.Errorf("nextDifficulty(%v, %v) = %v, want %v", tt.d, tt.grade, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetrievability(t *testing.T) {
+	tests := []struct {
+		name      string
+		elapsed   float64
+		stability float64
+		want      float64
+	}{
+		{"just reviewed", 0, 5, 1},
+		{"after one stability", 10, 10, 0.9},
+		{"no stability", 3, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Retrievability(tt.elapsed, tt.stability)
+			if math.Abs(got-tt.want) > fsrsTolerance {
+				t.Errorf("Retrievability(%v, %v) = %v, want %v", tt.elapsed, tt.stability, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFSRSNextInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    FSRSParams
+		stability float64
+		want      int
+	}{
+		{"default retention matches stability", DefaultFSRSParams(), 20, 20},
+		{"at least a day", DefaultFSRSParams(), 0.1, 1},
+		{"capped by the maximum", FSRSParams{DesiredRetention: 0.9, MaximumInterval: 30}, 100, 30},
+		{"invalid params fall back to defaults", FSRSParams{DesiredRetention: 2}, 20, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := FSRS{Params: tt.params}
+			if got := f.nextInterval(tt.stability); got != tt.want {
+				t.Errorf("nextInterval(%v) = %d, want %d", tt.stability, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFSRSIncompleteWeightsUseDefaults(t *testing.T) {
+	f := FSRS{Params: FSRSParams{Weights: []float64{1, 2, 3}}}
+	got := f.Schedule(ReviewState{}, GradeGood, time.Now())
+	if got.Stability != DefaultFSRSParams().Weights[2] {
+		t.Errorf("stability = %v, want the default weight %v", got.Stability, DefaultFSRSParams().Weights[2])
+	}
+}
@@ -3,19 +3,53 @@ package data
 
 import (
 	"fmt"
+	"math/rand"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// colorPattern matches the colour forms lipgloss understands: hex, or an
+// ANSI colour number that validColor range-checks.
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
+// validColor reports whether the colour is hex or an ANSI colour from 0 to
+// 255.
+func validColor(color string) bool {
+	if !colorPattern.MatchString(color) {
+		return false
+	}
+	if strings.HasPrefix(color, "#") {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n <= 255
+}
+
+// DeckInfo holds the user-editable metadata of a deck.
+type DeckInfo struct {
+	Name        string
+	Description string
+	Author      string
+	Color       string
+	Emoji       string
+	Shuffle     bool
+}
+
 type DeckManager struct {
-	decks map[uuid.UUID]*Deck
+	decks   map[uuid.UUID]*Deck
+	filters []FilteredDeck
+	index   *SearchIndex
 }
 
 func NewDeckManager() *DeckManager {
 	return &DeckManager{
 		decks: make(map[uuid.UUID]*Deck),
+		index: NewSearchIndex(),
 	}
 }
 
@@ -28,12 +62,65 @@ func (dm *DeckManager) LoadAllDecks() error {
 	}
 
 	dm.decks = make(map[uuid.UUID]*Deck)
+	dm.index = NewSearchIndex()
 	for _, deck := range decks {
+		// Backfill IDs for cards saved before cards had identities
+		if deck.EnsureCardIDs() {
+			if err := SaveDeck(deck); err != nil {
+				return err
+			}
+		}
 		dm.decks[deck.ID] = &deck
+		dm.indexDeck(&deck)
 	}
+
+	filters, err := LoadFilteredDecks()
+	if err != nil {
+		return err
+	}
+	dm.filters = filters
 	return nil
 }
 
+// indexDeck adds every card of a deck to the search index.
+func (dm *DeckManager) indexDeck(deck *Deck) {
+	for i := range deck.Cards {
+		dm.index.Add(deck.ID, &deck.Cards[i])
+	}
+}
+
+// Search looks for cards in every deck whose question, answer or tags
+// contain all words of the query, best matches first.
+func (dm *DeckManager) Search(query string) []SearchResult {
+	var words []string
+	for _, token := range tokenize(query) {
+		words = append(words, token.word)
+	}
+
+	var results []SearchResult
+	for _, hit := range dm.index.Search(query, SearchLimit) {
+		deck := dm.GetDeckByID(hit.deckID)
+		if deck == nil {
+			continue
+		}
+		card := deck.GetCard(hit.cardID)
+		if card == nil {
+			continue
+		}
+
+		field, text, highlights := snippet(card, words)
+		results = append(results, SearchResult{
+			Deck:       deck,
+			Card:       card,
+			Score:      hit.score,
+			Field:      field,
+			Snippet:    text,
+			Highlights: highlights,
+		})
+	}
+	return results
+}
+
 func (dm *DeckManager) GetDeckByID(id uuid.UUID) *Deck {
 	return dm.decks[id]
 }
@@ -61,6 +148,7 @@ func (dm *DeckManager) AddDeck(deck *Deck) error {
 	}
 
 	dm.decks[deck.ID] = deck
+	dm.indexDeck(deck)
 	return nil
 }
 
@@ -76,6 +164,7 @@ func (dm *DeckManager) RemoveDeck(id uuid.UUID) error {
 
 	// Delete reference from dm
 	delete(dm.decks, id)
+	dm.index.RemoveDeck(id)
 
 	return nil
 }
@@ -87,21 +176,173 @@ func (dm *DeckManager) AddCardToDeck(deckID uuid.UUID, card Card) error {
 	}
 
 	deck.AddCard(card)
+	deck.Touch()
+	dm.index.Add(deckID, &deck.Cards[len(deck.Cards)-1])
 
 	return SaveDeck(*deck)
 }
 
-func (dm *DeckManager) RemoveCardFromDeck(deckID uuid.UUID, cardIndex int) error {
+// GetCard returns the card with the given ID from a deck.
+func (dm *DeckManager) GetCard(deckID, cardID uuid.UUID) (*Card, error) {
+	deck := dm.GetDeckByID(deckID)
+	if deck == nil {
+		return nil, fmt.Errorf("deck not found with ID: %s", deckID)
+	}
+
+	card := deck.GetCard(cardID)
+	if card == nil {
+		return nil, fmt.Errorf("card not found with ID: %s", cardID)
+	}
+
+	return card, nil
+}
+
+// FindCard looks up a card by ID across all decks.
+func (dm *DeckManager) FindCard(cardID uuid.UUID) (*Deck, *Card) {
+	for _, deck := range dm.decks {
+		if card := deck.GetCard(cardID); card != nil {
+			return deck, card
+		}
+	}
+	return nil, nil
+}
+
+func (dm *DeckManager) RemoveCardFromDeck(deckID, cardID uuid.UUID) error {
 	deck := dm.GetDeckByID(deckID)
 	if deck == nil {
 		return fmt.Errorf("deck not found with ID: %s", deckID)
 	}
 
-	deck.RemoveCard(cardIndex)
+	index := deck.CardIndex(cardID)
+	if index < 0 {
+		return fmt.Errorf("card not found with ID: %s", cardID)
+	}
+
+	deck.RemoveCard(index)
+	deck.Touch()
+	dm.index.Remove(cardID)
+
+	return SaveDeck(*deck)
+}
+
+// UpdateCard replaces the content of an existing card, matched by ID, and
+// saves the deck. The card keeps its position, review state and history.
+func (dm *DeckManager) UpdateCard(deckID uuid.UUID, card Card) error {
+	existing, err := dm.GetCard(deckID, card.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Question = card.Question
+	existing.Answer = card.Answer
+	existing.Tags = card.Tags
+
+	switch {
+	case HasCloze(existing.Question):
+		existing.Type = CardTypeCloze
+	case existing.IsCloze():
+		existing.Type = CardTypeBasic
+	}
+
+	dm.index.Add(deckID, existing)
+
+	deck := dm.GetDeckByID(deckID)
+	deck.Touch()
+
+	return SaveDeck(*deck)
+}
+
+// RenameDeck changes the name of a deck.
+func (dm *DeckManager) RenameDeck(id uuid.UUID, name string) error {
+	deck := dm.GetDeckByID(id)
+	if deck == nil {
+		return fmt.Errorf("deck not found with ID: %s", id)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("deck name cannot be empty")
+	}
+
+	deck.Name = name
+	deck.Touch()
+
+	return SaveDeck(*deck)
+}
+
+// UpdateDeckInfo replaces the metadata of a deck.
+func (dm *DeckManager) UpdateDeckInfo(id uuid.UUID, info DeckInfo) error {
+	deck := dm.GetDeckByID(id)
+	if deck == nil {
+		return fmt.Errorf("deck not found with ID: %s", id)
+	}
+
+	info.Name = strings.TrimSpace(info.Name)
+	if info.Name == "" {
+		return fmt.Errorf("deck name cannot be empty")
+	}
+
+	info.Color = strings.TrimSpace(info.Color)
+	if info.Color != "" && !validColor(info.Color) {
+		return fmt.Errorf("invalid colour %q: use #RRGGBB or 0-255", info.Color)
+	}
+
+	deck.Name = info.Name
+	deck.Description = strings.TrimSpace(info.Description)
+	deck.Author = strings.TrimSpace(info.Author)
+	deck.Color = info.Color
+	deck.Emoji = strings.TrimSpace(info.Emoji)
+	if deck.Shuffle && !info.Shuffle {
+		// Leave the shuffled session too, or it would outlast the default
+		deck.Reshuffle(0, time.Now())
+	}
+	deck.Shuffle = info.Shuffle
+	deck.Touch()
 
 	return SaveDeck(*deck)
 }
 
+// DuplicateDeck copies a deck under a new ID. The copied cards get new IDs
+// and start with fresh review progress.
+func (dm *DeckManager) DuplicateDeck(id uuid.UUID) (*Deck, error) {
+	deck := dm.GetDeckByID(id)
+	if deck == nil {
+		return nil, fmt.Errorf("deck not found with ID: %s", id)
+	}
+
+	duplicate := NewDeck(deck.Name + " (copy)")
+	duplicate.Description = deck.Description
+	duplicate.Author = deck.Author
+	duplicate.Color = deck.Color
+	duplicate.Emoji = deck.Emoji
+	duplicate.Reverse = deck.Reverse
+	duplicate.MultipleChoice = deck.MultipleChoice
+	duplicate.Morse = deck.Morse
+	duplicate.PlainText = deck.PlainText
+	duplicate.Shuffle = deck.Shuffle
+	duplicate.Scheduler = deck.Scheduler
+	if deck.FSRS != nil {
+		params := *deck.FSRS
+		params.Weights = append([]float64(nil), deck.FSRS.Weights...)
+		duplicate.FSRS = &params
+	}
+
+	for _, card := range deck.Cards {
+		card.ID = uuid.Nil
+		card.Review = ReviewState{}
+		card.Items = nil
+		card.Tags = append([]string(nil), card.Tags...)
+		card.Distractors = append([]string(nil), card.Distractors...)
+		duplicate.AddCard(card)
+	}
+
+	if err := dm.AddDeck(duplicate); err != nil {
+		return nil, err
+	}
+
+	return duplicate, nil
+}
+
 func (dm *DeckManager) SaveDeckState(deckID uuid.UUID) error {
 	deck := dm.GetDeckByID(deckID)
 	if deck == nil {
@@ -111,6 +352,102 @@ func (dm *DeckManager) SaveDeckState(deckID uuid.UUID) error {
 	return SaveDeck(*deck)
 }
 
+// GetFilteredDecks returns the saved filtered decks sorted by name.
+func (dm *DeckManager) GetFilteredDecks() []FilteredDeck {
+	filters := append([]FilteredDeck(nil), dm.filters...)
+	sort.Slice(filters, func(i, j int) bool {
+		return strings.ToLower(filters[i].Name) < strings.ToLower(filters[j].Name)
+	})
+	return filters
+}
+
+// GetFilteredDeck returns the filtered deck with the given ID, or nil.
+func (dm *DeckManager) GetFilteredDeck(id uuid.UUID) *FilteredDeck {
+	for i := range dm.filters {
+		if dm.filters[i].ID == id {
+			return &dm.filters[i]
+		}
+	}
+	return nil
+}
+
+// SaveFilteredDeck adds a filtered deck, or replaces the one with the same ID,
+// and stores all filtered decks.
+func (dm *DeckManager) SaveFilteredDeck(f FilteredDeck) (FilteredDeck, error) {
+	f.Name = strings.TrimSpace(f.Name)
+	f.Query = strings.TrimSpace(f.Query)
+	if err := f.Validate(); err != nil {
+		return f, err
+	}
+
+	if existing := dm.GetFilteredDeck(f.ID); existing != nil && f.ID != uuid.Nil {
+		*existing = f
+	} else {
+		f.ID = uuid.New()
+		dm.filters = append(dm.filters, f)
+	}
+
+	return f, SaveFilteredDecks(dm.filters)
+}
+
+func (dm *DeckManager) RemoveFilteredDeck(id uuid.UUID) error {
+	for i, f := range dm.filters {
+		if f.ID == id {
+			dm.filters = append(dm.filters[:i], dm.filters[i+1:]...)
+			return SaveFilteredDecks(dm.filters)
+		}
+	}
+	return fmt.Errorf("filtered deck not found with ID: %s", id)
+}
+
+// FilterItems returns the review items of every deck that match the query,
+// with decks in alphabetical order and items in card order.
+func (dm *DeckManager) FilterItems(q Query, now time.Time) []SessionItem {
+	decks := dm.GetAllDecks()
+	dm.SortDecksAlphabetical(decks)
+
+	var items []SessionItem
+	for _, deck := range decks {
+		for _, item := range deck.Items() {
+			card := &deck.Cards[item.CardIndex]
+			if q.Match(deck, card, item.Key, now) {
+				items = append(items, SessionItem{Deck: deck, CardID: card.ID, Key: item.Key})
+			}
+		}
+	}
+	return items
+}
+
+// ChaosSession starts a session over the due items of every deck in random
+// order.
+func (dm *DeckManager) ChaosSession(name string, now time.Time, rng *rand.Rand) *Session {
+	decks := dm.GetAllDecks()
+	dm.SortDecksAlphabetical(decks)
+
+	var items []SessionItem
+	for _, deck := range decks {
+		for _, item := range deck.Items() {
+			card := &deck.Cards[item.CardIndex]
+			if card.State(item.Key).IsDue(now) {
+				items = append(items, SessionItem{Deck: deck, CardID: card.ID, Key: item.Key})
+			}
+		}
+	}
+
+	session := NewSession(name, items)
+	session.Shuffle(rng)
+	return session
+}
+
+// StudyFiltered starts a session over the items a filtered deck matches.
+func (dm *DeckManager) StudyFiltered(f FilteredDeck, now time.Time) (*Session, error) {
+	q, err := ParseQuery(f.Query)
+	if err != nil {
+		return nil, err
+	}
+	return NewSession(f.Name, dm.FilterItems(q, now)), nil
+}
+
 func (dm *DeckManager) SortDecksAlphabetical(decks []*Deck) {
 	sort.Slice(decks, func(i, j int) bool {
 		return strings.ToLower(decks[i].Name) < strings.ToLower(decks[j].Name)
@@ -0,0 +1,91 @@
+// data/morse.go
+package data
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MorseSettings sets the speed and pitch of Morse code audio.
+type MorseSettings struct {
+	// WPM is the speed each character is sent at
+	WPM int `yaml:"wpm"`
+
+	// FarnsworthWPM is the slower overall speed reached by stretching the
+	// gaps between characters and words; zero keeps standard spacing
+	FarnsworthWPM int `yaml:"farnsworth_wpm"`
+
+	ToneHz int `yaml:"tone_hz"`
+}
+
+func DefaultMorseSettings() MorseSettings {
+	return MorseSettings{
+		WPM:           20,
+		FarnsworthWPM: 10,
+		ToneHz:        600,
+	}
+}
+
+// morseCode holds the International Morse Code of every character that can
+// be sent.
+var morseCode = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.",
+	'G': "--.", 'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..",
+	'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+
+	'.': ".-.-.-", ',': "--..--", '?': "..--..", '\'': ".----.", '!': "-.-.--",
+	'/': "-..-.", '(': "-.--.", ')': "-.--.-", '&': ".-...", ':': "---...",
+	';': "-.-.-.", '=': "-...-", '+': ".-.-.", '-': "-....-", '_': "..--.-",
+	'"': ".-..-.", '$': "...-..-", '@': ".--.-.",
+}
+
+// MorseText returns text as it is sent in Morse code: upper case, without the
+// characters Morse has no code for and with words separated by single spaces.
+func MorseText(text string) string {
+	var words []string
+	for _, field := range strings.Fields(text) {
+		var word strings.Builder
+		for _, r := range field {
+			r = unicode.ToUpper(r)
+			if _, ok := morseCode[r]; ok {
+				word.WriteRune(r)
+			}
+		}
+		if word.Len() > 0 {
+			words = append(words, word.String())
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// EncodeMorse returns the Morse code of text with characters separated by
+// spaces and words by " / ", e.g. "SOS HI" -> "... --- ... / .... ..".
+func EncodeMorse(text string) string {
+	words := strings.Fields(MorseText(text))
+	for i, word := range words {
+		codes := make([]string, 0, len(word))
+		for _, r := range word {
+			codes = append(codes, morseCode[r])
+		}
+		words[i] = strings.Join(codes, " ")
+	}
+	return strings.Join(words, " / ")
+}
+
+// IsMorse reports whether the card is reviewed as Morse code: its question is
+// played as audio and typed back. Morse cards and the basic cards of Morse
+// decks qualify as long as their question can be sent.
+func (d *Deck) IsMorse(card *Card) bool {
+	if card == nil || card.IsCloze() {
+		return false
+	}
+	if card.Type != CardTypeMorse && !d.Morse {
+		return false
+	}
+	return MorseText(card.Question) != ""
+}
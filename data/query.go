@@ -0,0 +1,280 @@
+// data/query.go
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query selects review items across decks. A query is a list of terms that
+// must all match; a leading "-" negates a term:
+//
+//	tag:networking      the card has the tag (* and ? are wildcards)
+//	deck:"Go*"          the deck name matches (* and ? are wildcards)
+//	is:due              the item is due; also is:new, is:cloze, is:reverse
+//	lapses>3            compare lapses, reps or interval (days) with
+//	                    =, :, <, <=, > or >=
+//	subnet              the question or answer contains the word
+//
+// Values with spaces are quoted: deck:"Go basics" or "subnet mask".
+type Query struct {
+	source string
+	terms  []queryTerm
+}
+
+type queryTerm struct {
+	negate bool
+	field  string
+	op     string
+
+	// pattern matches tag and deck names; text is a lower-cased value; number
+	// is the operand of a comparison
+	pattern *regexp.Regexp
+	text    string
+	number  float64
+}
+
+// Query fields.
+const (
+	queryTag      = "tag"
+	queryDeck     = "deck"
+	queryIs       = "is"
+	queryLapses   = "lapses"
+	queryReps     = "reps"
+	queryInterval = "interval"
+)
+
+// queryStates are the values of is: terms.
+var queryStates = []string{"due", "new", "cloze", "reverse"}
+
+// queryOps are the comparison operators, longest first so that ">=" is not
+// read as ">".
+var queryOps = []string{">=", "<=", ">", "<", "=", ":"}
+
+// ParseQuery parses a query string. An empty query matches every item.
+func ParseQuery(s string) (Query, error) {
+	words, err := splitQuery(s)
+	if err != nil {
+		return Query{}, err
+	}
+
+	q := Query{source: strings.TrimSpace(s)}
+	for _, word := range words {
+		term, err := parseTerm(word)
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// String returns the query as it was written.
+func (q Query) String() string {
+	return q.source
+}
+
+// queryWord is a whitespace-separated part of a query. quoted is set if it
+// starts with a quote, possibly after a "-", which makes it plain text.
+type queryWord struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits a query on whitespace outside of double quotes and drops
+// the quotes.
+func splitQuery(s string) ([]queryWord, error) {
+	var words []queryWord
+	var current strings.Builder
+	inQuotes, started, quoted := false, false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			if prefix := current.String(); prefix == "" || prefix == "-" {
+				quoted = true
+			}
+			inQuotes = !inQuotes
+			started = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if started {
+				words = append(words, queryWord{current.String(), quoted})
+			}
+			current.Reset()
+			started, quoted = false, false
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+	if started {
+		words = append(words, queryWord{current.String(), quoted})
+	}
+	return words, nil
+}
+
+func parseTerm(word queryWord) (queryTerm, error) {
+	var term queryTerm
+
+	text := word.text
+	if len(text) > 1 && strings.HasPrefix(text, "-") {
+		term.negate = true
+		text = text[1:]
+	}
+
+	if !word.quoted {
+		for _, op := range queryOps {
+			i := strings.Index(text, op)
+			if i <= 0 {
+				continue
+			}
+			field := strings.ToLower(text[:i])
+			if !isQueryField(field) {
+				if op == ":" {
+					return term, fmt.Errorf("unknown query field %q (quote text containing \":\")", field)
+				}
+				continue
+			}
+			term.field, term.op = field, op
+			text = text[i+len(op):]
+			break
+		}
+	}
+
+	if text == "" {
+		return term, fmt.Errorf("missing value in query term %q", word.text)
+	}
+
+	switch term.field {
+	case queryTag, queryDeck:
+		if term.op != ":" {
+			return term, fmt.Errorf("use %s: to match a %s, e.g. %s:name", term.field, term.field, term.field)
+		}
+		term.pattern = globPattern(text)
+	case queryIs:
+		if term.op != ":" {
+			return term, fmt.Errorf("use is: to match a state, e.g. is:due")
+		}
+		term.text = strings.ToLower(text)
+		if !containsString(queryStates, term.text) {
+			return term, fmt.Errorf("unknown state %q: use %s", text, strings.Join(queryStates, ", "))
+		}
+	case queryLapses, queryReps, queryInterval:
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return term, fmt.Errorf("%s needs a number, got %q", term.field, text)
+		}
+		term.number = n
+	default:
+		term.text = strings.ToLower(text)
+	}
+
+	return term, nil
+}
+
+func isQueryField(field string) bool {
+	switch field {
+	case queryTag, queryDeck, queryIs, queryLapses, queryReps, queryInterval:
+		return true
+	}
+	return false
+}
+
+// globPattern compiles a case-insensitive pattern where * matches any text
+// and ? a single character.
+func globPattern(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the review item of card with the given key matches
+// every term of the query.
+func (q Query) Match(deck *Deck, card *Card, key string, now time.Time) bool {
+	for _, term := range q.terms {
+		if term.match(deck, card, key, now) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t queryTerm) match(deck *Deck, card *Card, key string, now time.Time) bool {
+	state := card.State(key)
+
+	switch t.field {
+	case queryTag:
+		for _, tag := range card.Tags {
+			if t.pattern.MatchString(tag) {
+				return true
+			}
+		}
+		return false
+	case queryDeck:
+		return t.pattern.MatchString(deck.Name)
+	case queryIs:
+		switch t.text {
+		case "due":
+			return state.IsDue(now)
+		case "new":
+			return state.IsNew()
+		case "cloze":
+			return card.IsCloze()
+		case "reverse":
+			return key == ReverseKey
+		}
+		return false
+	case queryLapses:
+		return compare(float64(state.Lapses), t.op, t.number)
+	case queryReps:
+		return compare(float64(state.Repetitions), t.op, t.number)
+	case queryInterval:
+		return compare(float64(state.Interval), t.op, t.number)
+	default:
+		return strings.Contains(strings.ToLower(card.Question), t.text) ||
+			strings.Contains(strings.ToLower(card.Answer), t.text)
+	}
+}
+
+func compare(value float64, op string, operand float64) bool {
+	switch op {
+	case ">":
+		return value > operand
+	case ">=":
+		return value >= operand
+	case "<":
+		return value < operand
+	case "<=":
+		return value <= operand
+	default:
+		return value == operand
+	}
+}
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unterminated quote", `deck:"Go basics`},
+		{"unknown field", "colour:red"},
+		{"missing value", "tag:"},
+		{"tag comparison", "tag>go"},
+		{"unknown state", "is:late"},
+		{"state comparison", "is>due"},
+		{"number expected", "lapses>many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseQuery(tt.query); err == nil {
+				t.Errorf("ParseQuery(%q) succeeded, want an error", tt.query)
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	deck := &Deck{Name: "Go basics"}
+	reviewed := &Card{
+		Question: "What does defer do?",
+		Answer:   "Delays a call until the function returns",
+		Tags:     []string{"go", "networking"},
+		Review: ReviewState{
+			Interval:    12,
+			Repetitions: 5,
+			Lapses:      4,
+			LastReview:  now.AddDate(0, 0, -12),
+			Due:         now.AddDate(0, 0, 1),
+		},
+		Items: map[string]ReviewState{
+			ReverseKey: {LastReview: now.AddDate(0, 0, -1), Due: now.AddDate(0, 0, -1)},
+		},
+	}
+	cloze := &Card{Type: CardTypeCloze, Question: "A {{c1::subnet mask}} splits a network"}
+
+	tests := []struct {
+		query string
+		card  *Card
+		key   string
+		want  bool
+	}{
+		{"", reviewed, "", true},
+
+		{"tag:go", reviewed, "", true},
+		{"tag:GO", reviewed, "", true},
+		{"tag:net*", reviewed, "", true},
+		{"tag:g?", reviewed, "", true},
+		{"tag:net", reviewed, "", false},
+		{"tag:go", cloze, "1", false},
+
+		{"deck:go*", reviewed, "", true},
+		{`deck:"Go basics"`, reviewed, "", true},
+		{"deck:basics", reviewed, "", false},
+
+		{"is:due", reviewed, "", false},
+		{"is:due", reviewed, ReverseKey, true},
+		{"is:new", reviewed, "", false},
+		{"is:new", cloze, "1", true},
+		{"is:cloze", cloze, "1", true},
+		{"is:cloze", reviewed, "", false},
+		{"is:reverse", reviewed, ReverseKey, true},
+		{"is:reverse", reviewed, "", false},
+
+		{"lapses>3", reviewed, "", true},
+		{"lapses>4", reviewed, "", false},
+		{"lapses>=4", reviewed, "", true},
+		{"lapses<4", reviewed, "", false},
+		{"lapses<=4", reviewed, "", true},
+		{"lapses=4", reviewed, "", true},
+		{"lapses:4", reviewed, "", true},
+		{"reps>=5", reviewed, "", true},
+		{"interval<10", reviewed, "", false},
+		{"interval>10", reviewed, "", true},
+
+		{"defer", reviewed, "", true},
+		{"FUNCTION", reviewed, "", true},
+		{`"subnet mask"`, cloze, "1", true},
+		{`"tag:go"`, reviewed, "", false},
+
+		{"-tag:go", reviewed, "", false},
+		{"-is:due", reviewed, "", true},
+		{`-"subnet mask"`, cloze, "1", false},
+		{"tag:go lapses>3", reviewed, "", true},
+		{"tag:go lapses>4", reviewed, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+			}
+			if got := q.Match(deck, tt.card, tt.key, now); got != tt.want {
+				t.Errorf("Match(%q, key %q) = %v, want %v", tt.query, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryString(t *testing.T) {
+	q, err := ParseQuery("  tag:go  lapses>3 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.String(), "tag:go  lapses>3"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+// data/reviewlog.go
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewLogFile is the append-only review history, kept next to DecksDir.
+// Each line is one JSON-encoded ReviewEntry so a crash can at most lose the
+// line being written.
+const ReviewLogFile = "reviews.jsonl"
+
+// Kinds of review log entries.
+const (
+	ReviewKindFlip  = "flip"
+	ReviewKindGrade = "grade"
+)
+
+// ReviewEntry is a single event in the review log.
+type ReviewEntry struct {
+	Time      time.Time    `json:"time"`
+	Kind      string       `json:"kind"`
+	DeckID    uuid.UUID    `json:"deck_id"`
+	CardID    uuid.UUID    `json:"card_id"`
+	Item      string       `json:"item,omitempty"`
+	Grade     Grade        `json:"grade,omitempty"`
+	Scheduler string       `json:"scheduler,omitempty"`
+	AnswerMs  int64        `json:"answer_ms"`
+	Before    *ReviewState `json:"before,omitempty"`
+	After     *ReviewState `json:"after,omitempty"`
+}
+
+// AnswerTime returns the time the user took to answer.
+func (e ReviewEntry) AnswerTime() time.Duration {
+	return time.Duration(e.AnswerMs) * time.Millisecond
+}
+
+// AppendReview writes a single entry to the end of the review log and syncs
+// it to disk.
+func AppendReview(entry ReviewEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal review entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	file, err := os.OpenFile(ReviewLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open review log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write review log: %w", err)
+	}
+
+	return file.Sync()
+}
+
+// LoadReviewLog reads every entry of the review log in the order they were
+// written. Lines that cannot be parsed, such as a partial line left behind by
+// a crash, are skipped.
+func LoadReviewLog() ([]ReviewEntry, error) {
+	file, err := os.Open(ReviewLogFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open review log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []ReviewEntry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry ReviewEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read review log: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,146 @@
+// data/scheduler.go
+package data
+
+import (
+	"math"
+	"time"
+)
+
+// Grade is the user's self-assessment after revealing an answer.
+type Grade int
+
+const (
+	GradeAgain Grade = iota + 1
+	GradeHard
+	GradeGood
+	GradeEasy
+)
+
+const (
+	DefaultEase = 2.5
+	MinEase     = 1.3
+)
+
+// Names of the available scheduling algorithms, as stored in settings and
+// deck files.
+const (
+	SchedulerSM2  = "sm2"
+	SchedulerFSRS = "fsrs"
+)
+
+// Scheduler computes the next review state of a card after it was graded.
+type Scheduler interface {
+	Name() string
+	Schedule(s ReviewState, grade Grade, now time.Time) ReviewState
+}
+
+// NewScheduler returns the scheduler with the given name. Unknown names fall
+// back to SM-2. params is only used by FSRS and may be nil.
+func NewScheduler(name string, params *FSRSParams) Scheduler {
+	switch name {
+	case SchedulerFSRS:
+		if params == nil {
+			defaults := DefaultFSRSParams()
+			params = &defaults
+		}
+		return FSRS{Params: *params}
+	default:
+		return SM2{}
+	}
+}
+
+func (g Grade) String() string {
+	switch g {
+	case GradeAgain:
+		return "again"
+	case GradeHard:
+		return "hard"
+	case GradeGood:
+		return "good"
+	case GradeEasy:
+		return "easy"
+	}
+	return "unknown"
+}
+
+// ReviewState holds the spaced-repetition state of a single card.
+type ReviewState struct {
+	Ease        float64   `yaml:"ease,omitempty" json:"ease,omitempty"`
+	Interval    int       `yaml:"interval,omitempty" json:"interval,omitempty"` // days
+	Repetitions int       `yaml:"repetitions,omitempty" json:"repetitions,omitempty"`
+	Lapses      int       `yaml:"lapses,omitempty" json:"lapses,omitempty"`
+	Stability   float64   `yaml:"stability,omitempty" json:"stability,omitempty"`   // FSRS, days
+	Difficulty  float64   `yaml:"difficulty,omitempty" json:"difficulty,omitempty"` // FSRS, 1-10
+	Due         time.Time `yaml:"due,omitempty" json:"due"`
+	LastReview  time.Time `yaml:"last_review,omitempty" json:"last_review"`
+}
+
+// IsNew reports whether the card has never been reviewed.
+func (s ReviewState) IsNew() bool {
+	return s.LastReview.IsZero()
+}
+
+// IsDue reports whether the card should be shown at the given time.
+func (s ReviewState) IsDue(now time.Time) bool {
+	return !s.Due.After(now)
+}
+
+// quality maps a grade onto the 0-5 scale used by SM-2.
+func (g Grade) quality() int {
+	switch g {
+	case GradeAgain:
+		return 1
+	case GradeHard:
+		return 3
+	case GradeGood:
+		return 4
+	case GradeEasy:
+		return 5
+	}
+	return 0
+}
+
+// SM2 implements the SuperMemo-2 algorithm.
+type SM2 struct{}
+
+func (SM2) Name() string { return SchedulerSM2 }
+
+// Schedule applies SM-2 to a review state and returns the updated state.
+// Failed cards are due again immediately so they come back in the same
+// session.
+func (SM2) Schedule(s ReviewState, grade Grade, now time.Time) ReviewState {
+	if s.Ease == 0 {
+		s.Ease = DefaultEase
+	}
+
+	q := grade.quality()
+
+	if q < 3 {
+		if s.Repetitions > 0 {
+			s.Lapses++
+		}
+		s.Repetitions = 0
+		s.Interval = 0
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.Ease))
+		}
+		s.Repetitions++
+	}
+
+	diff := float64(5 - q)
+	s.Ease += 0.1 - diff*(0.08+diff*0.02)
+	if s.Ease < MinEase {
+		s.Ease = MinEase
+	}
+
+	s.LastReview = now
+	s.Due = now.AddDate(0, 0, s.Interval)
+
+	return s
+}
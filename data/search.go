@@ -0,0 +1,339 @@
+// data/search.go
+package data
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// SearchLimit is the most results Search returns.
+const SearchLimit = 50
+
+// SnippetWidth is the approximate length of a search result snippet in
+// characters.
+const SnippetWidth = 60
+
+// SearchField is a part of a card that is searched.
+type SearchField int
+
+const (
+	FieldQuestion SearchField = iota
+	FieldAnswer
+	FieldTags
+)
+
+// fieldWeights rank matches in the question above tags above the answer.
+var fieldWeights = [...]float64{
+	FieldQuestion: 3,
+	FieldAnswer:   1,
+	FieldTags:     2,
+}
+
+// Span is a byte range within a string.
+type Span struct {
+	Start, End int
+}
+
+// SearchResult is a card matching a search, with a snippet of the field that
+// matched best. Highlights are the matched words within Snippet.
+type SearchResult struct {
+	Deck       *Deck
+	Card       *Card
+	Score      float64
+	Field      SearchField
+	Snippet    string
+	Highlights []Span
+}
+
+// SearchIndex is an in-memory inverted index from words to the cards that
+// contain them.
+type SearchIndex struct {
+	postings map[string]map[uuid.UUID]*[len(fieldWeights)]int
+	cards    map[uuid.UUID]indexedCard
+
+	// sorted holds every indexed word in order for prefix lookups. It is
+	// rebuilt on the first search after the index changes.
+	sorted []string
+}
+
+type indexedCard struct {
+	deckID uuid.UUID
+	words  []string
+}
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		postings: make(map[string]map[uuid.UUID]*[len(fieldWeights)]int),
+		cards:    make(map[uuid.UUID]indexedCard),
+	}
+}
+
+// Add indexes a card of a deck, replacing any earlier version of it.
+func (ix *SearchIndex) Add(deckID uuid.UUID, card *Card) {
+	ix.Remove(card.ID)
+
+	var words []string
+	for field, text := range cardFields(card) {
+		for _, token := range tokenize(text) {
+			postings := ix.postings[token.word]
+			if postings == nil {
+				postings = make(map[uuid.UUID]*[len(fieldWeights)]int)
+				ix.postings[token.word] = postings
+				ix.sorted = nil
+			}
+
+			counts := postings[card.ID]
+			if counts == nil {
+				counts = new([len(fieldWeights)]int)
+				postings[card.ID] = counts
+				words = append(words, token.word)
+			}
+			counts[field]++
+		}
+	}
+
+	ix.cards[card.ID] = indexedCard{deckID: deckID, words: words}
+}
+
+// Remove drops a card from the index.
+func (ix *SearchIndex) Remove(cardID uuid.UUID) {
+	indexed, ok := ix.cards[cardID]
+	if !ok {
+		return
+	}
+
+	for _, word := range indexed.words {
+		delete(ix.postings[word], cardID)
+		if len(ix.postings[word]) == 0 {
+			delete(ix.postings, word)
+			ix.sorted = nil
+		}
+	}
+	delete(ix.cards, cardID)
+}
+
+// RemoveDeck drops every card of a deck from the index.
+func (ix *SearchIndex) RemoveDeck(deckID uuid.UUID) {
+	for cardID, indexed := range ix.cards {
+		if indexed.deckID == deckID {
+			ix.Remove(cardID)
+		}
+	}
+}
+
+// searchHit is a card matched by Search.
+type searchHit struct {
+	deckID uuid.UUID
+	cardID uuid.UUID
+	score  float64
+}
+
+// Search returns up to limit cards containing every word of the query, best
+// first. Query words match indexed words they are a prefix of, so results
+// update while a word is being typed; whole-word matches rank higher.
+func (ix *SearchIndex) Search(query string, limit int) []searchHit {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	if ix.sorted == nil {
+		ix.sorted = make([]string, 0, len(ix.postings))
+		for word := range ix.postings {
+			ix.sorted = append(ix.sorted, word)
+		}
+		sort.Strings(ix.sorted)
+	}
+
+	total := float64(len(ix.cards))
+	var scores map[uuid.UUID]float64
+
+	for _, token := range tokens {
+		termScores := make(map[uuid.UUID]float64)
+
+		start := sort.SearchStrings(ix.sorted, token.word)
+		for _, word := range ix.sorted[start:] {
+			if !strings.HasPrefix(word, token.word) {
+				break
+			}
+
+			postings := ix.postings[word]
+			idf := math.Log(1 + total/float64(len(postings)))
+			if word != token.word {
+				idf /= 2
+			}
+
+			for cardID, counts := range postings {
+				for field, count := range counts {
+					termScores[cardID] += idf * fieldWeights[field] * float64(count)
+				}
+			}
+		}
+
+		// Every query word must match
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for cardID, score := range scores {
+			if extra, ok := termScores[cardID]; ok {
+				scores[cardID] = score + extra
+			} else {
+				delete(scores, cardID)
+			}
+		}
+	}
+
+	hits := make([]searchHit, 0, len(scores))
+	for cardID, score := range scores {
+		hits = append(hits, searchHit{
+			deckID: ix.cards[cardID].deckID,
+			cardID: cardID,
+			score:  score,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].cardID.String() < hits[j].cardID.String()
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// cardFields returns the searchable text of a card, indexed by SearchField.
+func cardFields(card *Card) [len(fieldWeights)]string {
+	return [...]string{
+		FieldQuestion: ClozeText(card.Question),
+		FieldAnswer:   card.Answer,
+		FieldTags:     strings.Join(card.Tags, " "),
+	}
+}
+
+// token is a lower-cased word and where it appears in the original text.
+type token struct {
+	word string
+	span Span
+}
+
+// tokenize splits text into lower-cased runs of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{strings.ToLower(text[start:i]), Span{start, i}})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(text[start:]), Span{start, len(text)}})
+	}
+
+	return tokens
+}
+
+// snippet picks the field of a card that best matches the query words and
+// cuts a window of about SnippetWidth characters around the first match. It
+// returns the field, the snippet and the matches within the snippet.
+func snippet(card *Card, words []string) (SearchField, string, []Span) {
+	fields := cardFields(card)
+
+	best, bestCount := FieldQuestion, -1
+	for field, text := range fields {
+		if count := len(matchSpans(text, words)); count > bestCount {
+			best, bestCount = SearchField(field), count
+		}
+	}
+
+	text := strings.Join(strings.Fields(fields[best]), " ")
+	spans := matchSpans(text, words)
+
+	start, end := 0, len(text)
+	if utf8.RuneCountInString(text) > SnippetWidth {
+		// Start a little before the first match, on a word boundary
+		if len(spans) > 0 {
+			start = spans[0].Start
+			for back := 0; start > 0 && back < SnippetWidth/4; back++ {
+				_, size := utf8.DecodeLastRuneInString(text[:start])
+				start -= size
+			}
+			if i := strings.IndexByte(text[start:], ' '); start > 0 && i >= 0 && i < spans[0].Start-start {
+				start += i + 1
+			}
+		}
+
+		end = start
+		n := 0
+		for ; end < len(text) && n < SnippetWidth; n++ {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+
+		// Near the end of the text, show more of what comes before instead
+		for ; start > 0 && n < SnippetWidth; n++ {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+	}
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(text) {
+		suffix = "…"
+	}
+
+	var highlights []Span
+	for _, span := range spans {
+		if span.Start >= start && span.End <= end {
+			highlights = append(highlights, Span{
+				Start: span.Start - start + len(prefix),
+				End:   span.End - start + len(prefix),
+			})
+		}
+	}
+
+	return best, prefix + text[start:end] + suffix, highlights
+}
+
+// matchSpans returns the parts of text matching the query words: the prefix
+// of every word in text that starts with a query word.
+func matchSpans(text string, words []string) []Span {
+	var spans []Span
+	for _, token := range tokenize(text) {
+		for _, word := range words {
+			if !strings.HasPrefix(token.word, word) {
+				continue
+			}
+
+			// Map the matched runes of the lower-cased word back to text
+			end := token.span.Start
+			for n := utf8.RuneCountInString(word); n > 0 && end < token.span.End; n-- {
+				_, size := utf8.DecodeRuneInString(text[end:])
+				end += size
+			}
+			spans = append(spans, Span{token.span.Start, end})
+			break
+		}
+	}
+	return spans
+}
@@ -0,0 +1,118 @@
+// data/session.go
+package data
+
+import (
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// SessionItem is a review item of a card in some deck.
+type SessionItem struct {
+	Deck   *Deck
+	CardID uuid.UUID
+	Key    string
+}
+
+// Card returns the card of the item, or nil if it has been removed.
+func (it SessionItem) Card() *Card {
+	return it.Deck.GetCard(it.CardID)
+}
+
+// Session is a study session over review items that may come from several
+// decks, e.g. the items matched by a filtered deck. Unlike studying a deck it
+// does not move the decks' current cards.
+type Session struct {
+	Name string
+
+	// Items holds the items still to review; Pos is the one on screen
+	Items []SessionItem
+	Pos   int
+
+	// Total is the number of items the session started with
+	Total int
+}
+
+func NewSession(name string, items []SessionItem) *Session {
+	return &Session{
+		Name:  name,
+		Items: items,
+		Total: len(items),
+	}
+}
+
+// Current returns the item on screen. It is false once every item has been
+// reviewed.
+func (s *Session) Current() (SessionItem, bool) {
+	if len(s.Items) == 0 {
+		return SessionItem{}, false
+	}
+	if s.Pos < 0 || s.Pos >= len(s.Items) {
+		s.Pos = 0
+	}
+	return s.Items[s.Pos], true
+}
+
+// Remaining returns the number of items left to review.
+func (s *Session) Remaining() int {
+	return len(s.Items)
+}
+
+// Next moves to the next item, wrapping around.
+func (s *Session) Next() {
+	if len(s.Items) > 0 {
+		s.Pos = (s.Pos + 1) % len(s.Items)
+	}
+}
+
+// Prev moves to the previous item, wrapping around.
+func (s *Session) Prev() {
+	if len(s.Items) > 0 {
+		s.Pos = (s.Pos - 1 + len(s.Items)) % len(s.Items)
+	}
+}
+
+// Answer finishes the current item after it was graded. An item graded again
+// goes to the back of the session to be reviewed once more.
+func (s *Session) Answer(grade Grade) {
+	item, ok := s.Current()
+	if !ok {
+		return
+	}
+
+	s.Items = append(s.Items[:s.Pos], s.Items[s.Pos+1:]...)
+	if grade == GradeAgain {
+		s.Items = append(s.Items, item)
+	}
+	if s.Pos >= len(s.Items) {
+		s.Pos = 0
+	}
+}
+
+// Shuffle puts the remaining items in a random order, starting from the
+// first.
+func (s *Session) Shuffle(rng *rand.Rand) {
+	rng.Shuffle(len(s.Items), func(i, j int) {
+		s.Items[i], s.Items[j] = s.Items[j], s.Items[i]
+	})
+	s.Pos = 0
+}
+
+// RemoveCard drops the items of a card that was deleted.
+func (s *Session) RemoveCard(cardID uuid.UUID) {
+	kept := s.Items[:0]
+	for i, item := range s.Items {
+		if item.CardID == cardID {
+			if i < s.Pos {
+				s.Pos--
+			}
+			s.Total--
+			continue
+		}
+		kept = append(kept, item)
+	}
+	s.Items = kept
+	if s.Pos >= len(s.Items) {
+		s.Pos = 0
+	}
+}
@@ -0,0 +1,197 @@
+// data/stats.go
+package data
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ForecastDays = 30
+	HardestCards = 5
+)
+
+// Stats summarises review history and scheduling state for a set of decks.
+type Stats struct {
+	TotalCards    int
+	DueNow        int // cards with at least one review item due
+	Reviews       int
+	ReviewedToday int
+	ReviewedWeek  int
+	MatureReviews int     // reviews of cards that were not new
+	Retention     float64 // share of MatureReviews not graded "again"
+	AverageAnswer time.Duration
+	Forecast      [ForecastDays]int // cards due on each of the next days, today first
+	Hardest       []CardStat
+
+	Daily         map[time.Time]int // reviews per day, keyed by StartOfDay
+	CurrentStreak int               // consecutive days with reviews up to today
+	LongestStreak int
+}
+
+// CardStat describes a single card for the hardest-cards ranking.
+type CardStat struct {
+	DeckName string
+	Card     *Card
+	Fails    int
+}
+
+// StartOfDay returns midnight of the day containing t, in t's location.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns midnight of the Monday of the week containing t.
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// ComputeStats derives statistics for the given decks from their cards and
+// the review log. Log entries of other decks are ignored.
+func ComputeStats(decks []*Deck, entries []ReviewEntry, now time.Time) Stats {
+	stats := Stats{Daily: make(map[time.Time]int)}
+
+	deckIDs := make(map[uuid.UUID]bool, len(decks))
+	for _, deck := range decks {
+		deckIDs[deck.ID] = true
+	}
+
+	today := StartOfDay(now)
+	week := StartOfWeek(now)
+
+	fails := make(map[uuid.UUID]int)
+	var recalled, matured int
+	var answerTotal time.Duration
+	var answered int
+
+	for _, entry := range entries {
+		if entry.Kind != ReviewKindGrade || !deckIDs[entry.DeckID] {
+			continue
+		}
+
+		stats.Reviews++
+		stats.Daily[StartOfDay(entry.Time.In(now.Location()))]++
+		if !entry.Time.Before(today) {
+			stats.ReviewedToday++
+		}
+		if !entry.Time.Before(week) {
+			stats.ReviewedWeek++
+		}
+
+		if entry.AnswerMs > 0 {
+			answerTotal += entry.AnswerTime()
+			answered++
+		}
+
+		if entry.Grade == GradeAgain {
+			fails[entry.CardID]++
+		}
+
+		if entry.Before != nil && !entry.Before.IsNew() {
+			matured++
+			if entry.Grade != GradeAgain {
+				recalled++
+			}
+		}
+	}
+
+	stats.CurrentStreak, stats.LongestStreak = streaks(stats.Daily, today)
+
+	stats.MatureReviews = matured
+	if matured > 0 {
+		stats.Retention = float64(recalled) / float64(matured)
+	}
+	if answered > 0 {
+		stats.AverageAnswer = answerTotal / time.Duration(answered)
+	}
+
+	for _, deck := range decks {
+		for i := range deck.Cards {
+			card := &deck.Cards[i]
+			stats.TotalCards++
+
+			due := false
+			for _, key := range deck.ItemKeys(card) {
+				state := card.State(key)
+				if state.IsDue(now) {
+					due = true
+				}
+
+				day := int(StartOfDay(state.Due.In(now.Location())).Sub(today).Hours() / 24)
+				if day < 0 {
+					day = 0
+				}
+				if day < ForecastDays {
+					stats.Forecast[day]++
+				}
+			}
+			if due {
+				stats.DueNow++
+			}
+
+			// Lapses survive a lost log; the log also counts fails of new cards
+			n := fails[card.ID]
+			if lapses := card.Lapses(); lapses > n {
+				n = lapses
+			}
+			if n > 0 {
+				stats.Hardest = append(stats.Hardest, CardStat{
+					DeckName: deck.Name,
+					Card:     card,
+					Fails:    n,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(stats.Hardest, func(i, j int) bool {
+		return stats.Hardest[i].Fails > stats.Hardest[j].Fails
+	})
+	if len(stats.Hardest) > HardestCards {
+		stats.Hardest = stats.Hardest[:HardestCards]
+	}
+
+	return stats
+}
+
+// streaks returns the current and longest runs of consecutive days with
+// reviews. The current streak is not broken by today having no reviews yet.
+func streaks(daily map[time.Time]int, today time.Time) (current, longest int) {
+	if len(daily) == 0 {
+		return 0, 0
+	}
+
+	days := make([]time.Time, 0, len(daily))
+	for day := range daily {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	run := 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	day := today
+	if daily[day] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for daily[day] > 0 {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
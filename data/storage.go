@@ -18,10 +18,34 @@ const (
 
 const SettingsFile = "settings.yaml"
 
+// What happens to a card that is not answered within the time limit.
+const (
+	TimeLimitFlip = "flip"
+	TimeLimitFail = "fail"
+)
+
 type Settings struct {
-	ChaosMode bool `yaml:"chaos_mode"`
-	ShowTimer bool `yaml:"show_timer"`
-	Audio     bool `yaml:"audio"`
+	ChaosMode   bool   `yaml:"chaos_mode"`
+	ShowTimer   bool   `yaml:"show_timer"`
+	Audio       bool   `yaml:"audio"`
+	Scheduler   string `yaml:"scheduler"`
+	TypedAnswer bool   `yaml:"typed_answer"`
+
+	// AudioCommand plays WAV audio read from standard input, e.g. "aplay
+	// -q"; empty picks an installed player
+	AudioCommand string `yaml:"audio_command,omitempty"`
+
+	// CardTimeLimit is the time in seconds to answer a card before
+	// TimeLimitAction flips or fails it; zero turns it off
+	CardTimeLimit   int    `yaml:"card_time_limit"`
+	TimeLimitAction string `yaml:"time_limit_action"`
+
+	// SessionMinutes ends a study session after this many minutes; zero
+	// turns it off
+	SessionMinutes int `yaml:"session_minutes"`
+
+	AnswerNormalization AnswerNormalization `yaml:"answer_normalization"`
+	Morse               MorseSettings       `yaml:"morse"`
 }
 
 func DefaultSettings() Settings {
@@ -29,6 +53,12 @@ func DefaultSettings() Settings {
 		ChaosMode: false,
 		ShowTimer: false,
 		Audio:     false,
+		Scheduler: SchedulerSM2,
+
+		TimeLimitAction: TimeLimitFlip,
+
+		AnswerNormalization: DefaultAnswerNormalization(),
+		Morse:               DefaultMorseSettings(),
 	}
 }
 
@@ -54,7 +84,7 @@ func LoadSettings() (Settings, error) {
 		return Settings{}, fmt.Errorf("failed to read settings file: %w", err)
 	}
 
-	var settings Settings
+	settings := DefaultSettings()
 	if err := yaml.Unmarshal(data, &settings); err != nil {
 		return Settings{}, fmt.Errorf("failed to parse settings file: %w", err)
 	}
@@ -17,16 +17,10 @@ import (
 // No empty tags
 
 // TODO: For fun:
-// Chaos mode: random card selection from all available decks
 // Random answer color toggle
-// Ability randomize order of cards
 
 // Functionality:
-// Sound effects: Morse code
-// Optional timer
 // Smart tracking of the cards you fail
-// Search for decks with 's' and '/'?
-// Multichoice questions
 // Emoji toggle
 // Customizable colors
 
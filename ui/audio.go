@@ -0,0 +1,74 @@
+// ui/audio.go
+package ui
+
+import (
+	"go-flashcards/audio"
+	"go-flashcards/data"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Choices for the Morse settings in words per minute, cycled through in the
+// settings screen. A zero Farnsworth speed keeps standard spacing.
+var (
+	morseSpeeds      = []int{15, 18, 20, 25, 30}
+	farnsworthSpeeds = []int{0, 5, 8, 10, 13, 15}
+)
+
+// audioDoneMsg reports that a sound finished playing, or why it could not.
+type audioDoneMsg struct {
+	err error
+}
+
+// morseTiming returns the Morse timing from the settings.
+func (m model) morseTiming() audio.MorseTiming {
+	return audio.MorseTiming{
+		WPM:           m.settings.Morse.WPM,
+		FarnsworthWPM: m.settings.Morse.FarnsworthWPM,
+		ToneHz:        float64(m.settings.Morse.ToneHz),
+	}
+}
+
+// canPlay reports whether an audio player is installed.
+func (m model) canPlay() bool {
+	_, silent := m.player.(audio.Null)
+	return !silent
+}
+
+// playEffect queues a feedback sound when sound effects are on. Queued
+// sounds are played once the update is done.
+func (m *model) playEffect(e audio.Effect) {
+	if m.settings.Audio {
+		m.sounds = append(m.sounds, e.WAV())
+	}
+}
+
+// playFeedback queues the sound for an answer given a grade: wrong for
+// again, correct for any other grade.
+func (m *model) playFeedback(grade data.Grade) {
+	if grade == data.GradeAgain {
+		m.playEffect(audio.EffectWrong)
+	} else {
+		m.playEffect(audio.EffectCorrect)
+	}
+}
+
+// morseWAV returns the question of the Morse card on screen as Morse code
+// audio.
+func (m model) morseWAV() []byte {
+	card, _ := m.studyItem()
+	return audio.WAV(audio.Morse(data.EncodeMorse(card.Question), m.morseTiming()))
+}
+
+// play plays sounds one after the other in the background.
+func (m model) play(sounds ...[]byte) tea.Cmd {
+	player := m.player
+	return func() tea.Msg {
+		for _, wav := range sounds {
+			if err := player.Play(wav); err != nil {
+				return audioDoneMsg{err: err}
+			}
+		}
+		return audioDoneMsg{}
+	}
+}
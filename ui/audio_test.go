@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"os"
+	"testing"
+
+	"go-flashcards/audio"
+	"go-flashcards/data"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newStudyModel returns a model studying a new deck of cards in a temporary
+// directory, with sound effects on and audio going to the returned recorder.
+func newStudyModel(t *testing.T, morse bool, cards ...data.Card) (model, *audio.Recorder) {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+	if err := data.EnsureDirectories(); err != nil {
+		t.Fatal(err)
+	}
+
+	dm := data.NewDeckManager()
+	deck := data.NewDeck("Test")
+	deck.Morse = morse
+	if err := dm.AddDeck(deck); err != nil {
+		t.Fatal(err)
+	}
+	for _, card := range cards {
+		if err := dm.AddCardToDeck(deck.ID, card); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := &audio.Recorder{}
+	m := NewModel(dm)
+	m.settings = data.DefaultSettings()
+	m.settings.Audio = true
+	m.player = rec
+	// A blinking cursor would leave commands that wait for the next blink
+	m.typedInput.Cursor.SetMode(cursor.CursorStatic)
+	m.currentDeck = dm.GetDeckByID(deck.ID)
+	m.mode = ModeViewCard
+	m.presentCard()
+	return m, rec
+}
+
+// press sends keys to the model one at a time and runs the commands they
+// return, so queued sounds reach the player.
+func press(t *testing.T, m model, keys ...string) model {
+	t.Helper()
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(k)}
+		}
+		next, cmd := m.Update(msg)
+		m = next.(model)
+		runCmd(cmd)
+	}
+	return m
+}
+
+// runCmd runs a command and any commands batched into it.
+func runCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runCmd(c)
+		}
+	}
+}
+
+// checkPlayed compares the sounds played with the ones wanted, in order.
+func checkPlayed(t *testing.T, rec *audio.Recorder, want ...[]byte) {
+	t.Helper()
+	played := rec.Played()
+	if len(played) != len(want) {
+		t.Fatalf("played %d sounds, want %d", len(played), len(want))
+	}
+	for i := range want {
+		if string(played[i]) != string(want[i]) {
+			t.Errorf("sound %d is not the one wanted", i)
+		}
+	}
+}
+
+func TestFeedbackSounds(t *testing.T) {
+	cards := []data.Card{
+		{Question: "What keyword delays a call?", Answer: "defer"},
+		{Question: "What starts a goroutine?", Answer: "go"},
+	}
+
+	tests := []struct {
+		name  string
+		audio bool
+		keys  []string
+		want  [][]byte
+	}{
+		{"again is wrong", true, []string{" ", "1"}, [][]byte{audio.EffectWrong.WAV()}},
+		{"hard is correct", true, []string{" ", "2"}, [][]byte{audio.EffectCorrect.WAV()}},
+		{"good is correct", true, []string{" ", "3"}, [][]byte{audio.EffectCorrect.WAV()}},
+		{"flipping is silent", true, []string{" "}, nil},
+		{"effects off", false, []string{" ", "3"}, nil},
+		{
+			"last card completes",
+			true,
+			[]string{" ", "3", " ", "4"},
+			[][]byte{audio.EffectCorrect.WAV(), audio.EffectCorrect.WAV(), audio.EffectComplete.WAV()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, rec := newStudyModel(t, false, cards...)
+			m.settings.Audio = tt.audio
+			press(t, m, tt.keys...)
+			checkPlayed(t, rec, tt.want...)
+		})
+	}
+}
+
+func TestMorseSounds(t *testing.T) {
+	cards := []data.Card{
+		{Question: "SOS", Answer: "distress"},
+		{Question: "CQ", Answer: "calling"},
+	}
+	morse := func(text string) []byte {
+		timing := audio.MorseTiming{
+			WPM:           data.DefaultMorseSettings().WPM,
+			FarnsworthWPM: data.DefaultMorseSettings().FarnsworthWPM,
+			ToneHz:        float64(data.DefaultMorseSettings().ToneHz),
+		}
+		return audio.WAV(audio.Morse(data.EncodeMorse(text), timing))
+	}
+
+	tests := []struct {
+		name string
+		keys []string
+		want [][]byte
+	}{
+		{"shown card plays", nil, [][]byte{morse("SOS")}},
+		{"replay", []string{"ctrl+p"}, [][]byte{morse("SOS"), morse("SOS")}},
+		{"right answer", []string{"s", "o", "s", "enter"}, [][]byte{morse("SOS"), audio.EffectCorrect.WAV()}},
+		{"wrong answer", []string{"x", "enter"}, [][]byte{morse("SOS"), audio.EffectWrong.WAV()}},
+		{
+			"next card plays after the grade",
+			[]string{"s", "o", "s", "enter", "enter"},
+			[][]byte{morse("SOS"), audio.EffectCorrect.WAV(), morse("CQ")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, rec := newStudyModel(t, true, cards...)
+
+			// The question is queued when the card comes on screen and
+			// played after the update
+			next, cmd := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+			runCmd(cmd)
+			m = next.(model)
+
+			for _, k := range tt.keys {
+				if k == "ctrl+p" {
+					next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+					runCmd(cmd)
+					m = next.(model)
+					continue
+				}
+				m = press(t, m, k)
+			}
+			checkPlayed(t, rec, tt.want...)
+		})
+	}
+}
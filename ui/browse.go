@@ -0,0 +1,202 @@
+// ui/browse.go
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-flashcards/data"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/uuid"
+)
+
+// browseColumns are the card browser's columns; the number keys sort by them
+// in this order.
+var browseColumns = []table.Column{
+	{Title: "Question", Width: 30},
+	{Title: "Answer", Width: 22},
+	{Title: "Tags", Width: 14},
+	{Title: "Due", Width: 12},
+	{Title: "Lapses", Width: 6},
+}
+
+const (
+	sortQuestion = iota
+	sortAnswer
+	sortTags
+	sortDue
+	sortLapses
+)
+
+func newCardTable() table.Model {
+	t := table.New(
+		table.WithColumns(browseColumns),
+		table.WithFocused(true),
+		table.WithHeight(10),
+	)
+
+	styles := table.DefaultStyles()
+	styles.Header = TableHeaderStyle
+	styles.Selected = TableSelectedStyle
+	t.SetStyles(styles)
+
+	return t
+}
+
+// openBrowser shows the cards of the current deck in the card browser. Back
+// returns to the screen it was opened from.
+func (m *model) openBrowser() {
+	m.browseFrom = m.mode
+	m.mode = ModeBrowseCards
+	m.filtering = false
+	m.browseFilter.Blur()
+	m.browseFilter.Reset()
+	m.refreshBrowser()
+	m.cardTable.SetCursor(0)
+}
+
+// refreshBrowser rebuilds the table rows from the current deck, applying the
+// filter and sort order. The selected card stays selected if it is still
+// listed.
+func (m *model) refreshBrowser() {
+	var selected uuid.UUID
+	if i := m.cardTable.Cursor(); i >= 0 && i < len(m.browseIDs) {
+		selected = m.browseIDs[i]
+	}
+
+	deck := m.currentDeck
+	now := time.Now()
+	filter := strings.ToLower(strings.TrimSpace(m.browseFilter.Value()))
+
+	cards := make([]*data.Card, 0, len(deck.Cards))
+	for i := range deck.Cards {
+		if filter == "" || cardMatches(&deck.Cards[i], filter) {
+			cards = append(cards, &deck.Cards[i])
+		}
+	}
+
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, b := cards[i], cards[j]
+		if m.browseDesc {
+			a, b = b, a
+		}
+
+		switch m.browseSort {
+		case sortAnswer:
+			return strings.ToLower(a.Answer) < strings.ToLower(b.Answer)
+		case sortTags:
+			return strings.ToLower(strings.Join(a.Tags, ",")) < strings.ToLower(strings.Join(b.Tags, ","))
+		case sortDue:
+			return deck.CardDue(a).Before(deck.CardDue(b))
+		case sortLapses:
+			return a.Lapses() < b.Lapses()
+		default:
+			return strings.ToLower(a.Question) < strings.ToLower(b.Question)
+		}
+	})
+
+	columns := make([]table.Column, len(browseColumns))
+	copy(columns, browseColumns)
+	arrow := " ▲"
+	if m.browseDesc {
+		arrow = " ▼"
+	}
+	columns[m.browseSort].Title += arrow
+
+	rows := make([]table.Row, len(cards))
+	m.browseIDs = make([]uuid.UUID, len(cards))
+	cursor := 0
+	for i, card := range cards {
+		question := card.Question
+		if card.IsCloze() {
+			question = data.ClozeText(question)
+		}
+
+		rows[i] = table.Row{
+			cellText(question, columns[sortQuestion].Width),
+			cellText(card.Answer, columns[sortAnswer].Width),
+			cellText(strings.Join(card.Tags, ", "), columns[sortTags].Width),
+			formatCardDue(deck.CardDue(card), now),
+			fmt.Sprintf("%d", card.Lapses()),
+		}
+		m.browseIDs[i] = card.ID
+		if card.ID == selected {
+			cursor = i
+		}
+	}
+
+	m.cardTable.SetColumns(columns)
+	m.cardTable.SetRows(rows)
+	m.cardTable.SetCursor(cursor)
+}
+
+// selectBrowsedCard makes the card selected in the browser the deck's current
+// card and returns it.
+func (m *model) selectBrowsedCard() *data.Card {
+	card := m.browsedCard()
+	if card == nil || !m.currentDeck.SelectCard(card.ID) {
+		return nil
+	}
+	return m.currentDeck.CurrentCard()
+}
+
+// browsedCard returns the card selected in the browser, or nil.
+func (m model) browsedCard() *data.Card {
+	i := m.cardTable.Cursor()
+	if i < 0 || i >= len(m.browseIDs) {
+		return nil
+	}
+	return m.currentDeck.GetCard(m.browseIDs[i])
+}
+
+// leaveBrowser returns from the card browser to the deck list or to studying
+// the deck, whichever it was opened from.
+func (m *model) leaveBrowser() tea.Cmd {
+	if m.browseFrom == ModeDeckList {
+		return m.leaveStudy()
+	}
+	m.currentDeck.SeekDue(time.Now())
+	m.mode = ModeViewCard
+	m.presentCard()
+	return nil
+}
+
+// cardMatches reports whether the question, answer or a tag of card contains
+// filter, which must be lower case.
+func cardMatches(card *data.Card, filter string) bool {
+	if strings.Contains(strings.ToLower(card.Question), filter) ||
+		strings.Contains(strings.ToLower(card.Answer), filter) {
+		return true
+	}
+	for _, tag := range card.Tags {
+		if strings.Contains(strings.ToLower(tag), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// cellText fits the first line of s into a table cell.
+func cellText(s string, width int) string {
+	line := firstLine(s)
+	if line != s {
+		line += " …"
+	}
+	return ansi.Truncate(line, width, "…")
+}
+
+func formatCardDue(due, now time.Time) string {
+	switch {
+	case due.IsZero():
+		return "new"
+	case !due.After(now):
+		return "now"
+	default:
+		return formatDue(due, now)
+	}
+}
@@ -0,0 +1,124 @@
+// ui/code.go
+package ui
+
+import (
+	"log"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+// MaxCodeWidth is the widest a code line is shown before it is cut off. Code
+// is never wrapped; cards grow to fit lines up to this width.
+const MaxCodeWidth = 100
+
+// codeStyle is the chroma theme for code blocks.
+const codeStyle = "monokai"
+
+// faceSegment is a run of prose or a fenced code block within a card face.
+type faceSegment struct {
+	code bool
+	lang string
+	text string
+}
+
+// splitFences splits card text into prose and ``` or ~~~ fenced code blocks.
+// An unclosed fence runs to the end of the text.
+func splitFences(text string) []faceSegment {
+	var segments []faceSegment
+	var current []string
+	var fence string
+	var lang string
+
+	flush := func(code bool) {
+		if code || strings.TrimSpace(strings.Join(current, "\n")) != "" {
+			segments = append(segments, faceSegment{
+				code: code,
+				lang: lang,
+				text: strings.Join(current, "\n"),
+			})
+		}
+		current = nil
+		lang = ""
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if fence == "" {
+			if open := fenceMarker(trimmed); open != "" && indent < 4 {
+				flush(false)
+				fence = open
+				if fields := strings.Fields(trimmed[len(open):]); len(fields) > 0 {
+					lang = fields[0]
+				}
+				continue
+			}
+		} else if indent < 4 && strings.HasPrefix(trimmed, fence) &&
+			strings.Trim(strings.TrimSpace(trimmed), fence[:1]) == "" {
+			flush(true)
+			fence = ""
+			continue
+		}
+
+		current = append(current, line)
+	}
+
+	flush(fence != "")
+	return segments
+}
+
+// fenceMarker returns the opening fence of a line, e.g. "```", or "" if the
+// line does not open a code block.
+func fenceMarker(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// renderCode renders a code block left-aligned in a block at least width
+// wide, highlighted when it has a language hint and the terminal has colours.
+// Indentation is kept and tabs become four spaces.
+func renderCode(lang, code string, width int) string {
+	code = strings.ReplaceAll(code, "\t", "    ")
+
+	if formatter := codeFormatter(); lang != "" && formatter != "" {
+		var b strings.Builder
+		if err := quick.Highlight(&b, code, lang, formatter, codeStyle); err != nil {
+			log.Printf("Error highlighting code: %v", err)
+		} else {
+			code = strings.TrimRight(b.String(), "\n")
+		}
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, MaxCodeWidth, "…")
+	}
+
+	block := CodeBlockStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.PlaceHorizontal(max(width, lipgloss.Width(block)), lipgloss.Left, block)
+}
+
+// codeFormatter picks the chroma formatter for the terminal's colour
+// support, or "" if it has none.
+func codeFormatter() string {
+	switch lipgloss.ColorProfile() {
+	case termenv.TrueColor:
+		return "terminal16m"
+	case termenv.ANSI256:
+		return "terminal256"
+	case termenv.ANSI:
+		return "terminal16"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,174 @@
+// ui/editor.go
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go-flashcards/data"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+)
+
+// editorFinishedMsg is sent when the external editor exits.
+type editorFinishedMsg struct {
+	path     string
+	deckID   uuid.UUID
+	cardID   uuid.UUID // uuid.Nil for a new card
+	original []byte
+	err      error
+}
+
+// editorCommand builds the command for the user's editor, taken from $VISUAL
+// or $EDITOR and falling back to vi. The variable may include arguments,
+// e.g. "code --wait".
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// openInEditor writes a card to a temporary file and opens it in the external
+// editor. A draft left behind by a malformed edit of the same card is reopened
+// instead, so the user can fix it rather than start over.
+func (m *model) openInEditor(cardID uuid.UUID, f data.CardFile) tea.Cmd {
+	m.editorErr = ""
+
+	deckID := m.currentDeck.ID
+	path := m.editorDraft
+	if path == "" || m.editorDraftDeck != deckID || m.editorDraftCard != cardID {
+		m.discardEditorDraft()
+
+		content, err := data.MarshalCardFile(f)
+		if err != nil {
+			m.editorErr = err.Error()
+			return nil
+		}
+
+		path, err = writeTempCard(content)
+		if err != nil {
+			m.editorErr = err.Error()
+			return nil
+		}
+	}
+	m.editorDraft = ""
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		m.editorErr = fmt.Sprintf("failed to read card file: %v", err)
+		return nil
+	}
+
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return editorFinishedMsg{
+			path:     path,
+			deckID:   deckID,
+			cardID:   cardID,
+			original: original,
+			err:      err,
+		}
+	})
+}
+
+func writeTempCard(content []byte) (string, error) {
+	file, err := os.CreateTemp("", "flashdeck-card-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create card file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write card file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+// finishEditing reads the edited card back and saves it. A malformed edit is
+// kept as a draft and the error is shown.
+func (m *model) finishEditing(msg editorFinishedMsg) tea.Cmd {
+	if msg.err != nil {
+		// The editor failed or was aborted, e.g. with :cq
+		os.Remove(msg.path)
+		m.editorErr = fmt.Sprintf("editor exited with an error: %v", msg.err)
+		return nil
+	}
+
+	content, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.editorErr = fmt.Sprintf("failed to read card file: %v", err)
+		return nil
+	}
+
+	if bytes.Equal(content, msg.original) {
+		// Nothing changed
+		os.Remove(msg.path)
+		return nil
+	}
+
+	f, err := data.ParseCardFile(content)
+	if err != nil {
+		m.editorErr = err.Error() + " (press ctrl+o to fix it)"
+		m.editorDraft = msg.path
+		m.editorDraftDeck = msg.deckID
+		m.editorDraftCard = msg.cardID
+		return nil
+	}
+	os.Remove(msg.path)
+
+	card := f.Card()
+	if msg.cardID == uuid.Nil {
+		if err := m.deckManager.AddCardToDeck(msg.deckID, card); err != nil {
+			log.Printf("Error adding card: %v", err)
+		}
+		cmd := UpdateDeckList(m.deckManager, &m.list)
+
+		m.questionInput.Reset()
+		m.answerInput.Reset()
+		m.tagsInput.Reset()
+
+		m.activeInput = 0
+		m.questionInput.Focus()
+		m.answerInput.Blur()
+		m.tagsInput.Blur()
+		return cmd
+	}
+
+	card.ID = msg.cardID
+	if err := m.deckManager.UpdateCard(msg.deckID, card); err != nil {
+		log.Printf("Error updating card: %v", err)
+	}
+
+	if m.mode == ModeEditCard {
+		m.questionInput.Blur()
+		m.answerInput.Blur()
+		m.tagsInput.Blur()
+		m.leaveCardEditor()
+		return nil
+	}
+	m.presentCard()
+
+	return nil
+}
+
+// discardEditorDraft removes a draft left behind by a malformed edit.
+func (m *model) discardEditorDraft() {
+	if m.editorDraft != "" {
+		os.Remove(m.editorDraft)
+		m.editorDraft = ""
+	}
+}
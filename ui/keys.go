@@ -2,6 +2,8 @@
 package ui
 
 import (
+	"slices"
+
 	"github.com/charmbracelet/bubbles/key"
 )
 
@@ -15,15 +17,47 @@ type keyMap struct {
 	Prev       key.Binding
 	Back       key.Binding
 	Quit       key.Binding
+	ForceQuit  key.Binding
 	Help       key.Binding
 	Settings   key.Binding
 	Toggle     key.Binding
 	CreateDeck key.Binding
 	CreateCard key.Binding
 	DeleteDeck key.Binding
+	EditDeck   key.Binding
+	CopyDeck   key.Binding
 	DeleteCard key.Binding
+	EditCard   key.Binding
 	Yes        key.Binding
 	No         key.Binding
+	Again      key.Binding
+	Hard       key.Binding
+	Good       key.Binding
+	Easy       key.Binding
+	Stats      key.Binding
+
+	Choose        key.Binding // bound to the options on screen by choiceKeys
+	Continue      key.Binding
+	ToggleChoice  key.Binding
+	ToggleReverse key.Binding
+	Submit        key.Binding
+	Save          key.Binding
+	NextField     key.Binding
+	OpenEditor    key.Binding
+	ToggleFormat  key.Binding
+	Browse        key.Binding
+	Filter        key.Binding
+	SortColumn    key.Binding
+	Study         key.Binding
+	Search        key.Binding
+	PrevResult    key.Binding
+	NextResult    key.Binding
+	NewFilter     key.Binding
+	ToggleShuffle key.Binding
+	Reshuffle     key.Binding
+	DeckShuffle   key.Binding
+	ToggleMorse   key.Binding
+	ReplayMorse   key.Binding
 }
 
 // Main menu keymap
@@ -57,9 +91,13 @@ var keys = keyMap{
 		key.WithHelp("esc", "back"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
+		key.WithKeys("q"),
 		key.WithHelp("q", "quit"),
 	),
+	ForceQuit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit"),
+	),
 	Settings: key.NewBinding(
 		key.WithKeys("s"),
 		key.WithHelp("s", "settings"),
@@ -72,6 +110,14 @@ var keys = keyMap{
 		key.WithKeys("n"),
 		key.WithHelp("n", "new deck"),
 	),
+	EditDeck: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "deck properties"),
+	),
+	CopyDeck: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "duplicate deck"),
+	),
 	CreateCard: key.NewBinding(
 		key.WithKeys("c"),
 		key.WithHelp("c", "new card"),
@@ -84,6 +130,10 @@ var keys = keyMap{
 		key.WithKeys("d"),
 		key.WithHelp("d", "delete card"),
 	),
+	EditCard: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit card"),
+	),
 	Yes: key.NewBinding(
 		key.WithKeys("y"),
 		key.WithHelp("y", "yes"),
@@ -92,6 +142,122 @@ var keys = keyMap{
 		key.WithKeys("n"),
 		key.WithHelp("n", "no"),
 	),
+	Again: key.NewBinding(
+		key.WithKeys("1"),
+		key.WithHelp("1", "again"),
+	),
+	Hard: key.NewBinding(
+		key.WithKeys("2"),
+		key.WithHelp("2", "hard"),
+	),
+	Good: key.NewBinding(
+		key.WithKeys("3"),
+		key.WithHelp("3", "good"),
+	),
+	Easy: key.NewBinding(
+		key.WithKeys("4"),
+		key.WithHelp("4", "easy"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "statistics"),
+	),
+	Continue: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "accept grade"),
+	),
+	ToggleChoice: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "multiple choice"),
+	),
+	ToggleReverse: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "both directions"),
+	),
+	Submit: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "check answer"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save card"),
+	),
+	NextField: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next field"),
+	),
+	OpenEditor: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "open in $EDITOR"),
+	),
+	ToggleFormat: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "markdown/plain"),
+	),
+	Browse: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "browse cards"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	SortColumn: key.NewBinding(
+		key.WithKeys("1", "2", "3", "4", "5"),
+		key.WithHelp("1-5", "sort by column"),
+	),
+	Study: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "study card"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "search cards"),
+	),
+	PrevResult: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous"),
+	),
+	NextResult: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next"),
+	),
+	NewFilter: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "filtered deck"),
+	),
+	ToggleShuffle: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "shuffle on/off"),
+	),
+	Reshuffle: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "reshuffle"),
+	),
+	DeckShuffle: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "shuffle by default"),
+	),
+	ToggleMorse: key.NewBinding(
+		key.WithKeys("."),
+		key.WithHelp(".", "morse mode"),
+	),
+	ReplayMorse: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "replay morse"),
+	),
+}
+
+// choiceKeys binds the letters of n multiple-choice options, from "a".
+func choiceKeys(n int) key.Binding {
+	letters := make([]string, n)
+	for i := range letters {
+		letters[i] = string(rune('a' + i))
+	}
+	return key.NewBinding(
+		key.WithKeys(letters...),
+		key.WithHelp(letters[0]+"-"+letters[n-1], "choose"),
+	)
 }
 
 func (m model) getKeysForMode() []key.Binding {
@@ -99,12 +265,41 @@ func (m model) getKeysForMode() []key.Binding {
 
 	switch m.mode {
 	case ModeDeckList:
-		if m.deckManager.GetNumDecks() != 0 {
+		if m.list.SettingFilter() {
+			applyEnter := key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "apply filter"),
+			)
+			clearEsc := key.NewBinding(
+				key.WithKeys("esc"),
+				key.WithHelp("esc", "clear filter"),
+			)
+
+			keys = []key.Binding{
+				applyEnter,
+				clearEsc,
+			}
+		} else if m.deckManager.GetNumDecks() != 0 {
+			study := m.keys.Enter
+			if m.settings.ChaosMode {
+				study = key.NewBinding(
+					key.WithKeys("enter"),
+					key.WithHelp("enter", "chaos session"),
+				)
+			}
+
 			keys = []key.Binding{
 				m.keys.Up,
 				m.keys.Down,
-				m.keys.Enter,
+				study,
+				m.keys.Filter,
 				m.keys.CreateDeck,
+				m.keys.NewFilter,
+				m.keys.EditDeck,
+				m.keys.CopyDeck,
+				m.keys.Browse,
+				m.keys.Search,
+				m.keys.Stats,
 			}
 		} else {
 			keys = []key.Binding{
@@ -112,10 +307,66 @@ func (m model) getKeysForMode() []key.Binding {
 			}
 		}
 	case ModeViewCard:
-		if m.currentDeck == nil || len(m.currentDeck.Cards) == 0 {
-			// For empty decks
+		if m.nothingToStudy() {
+			// For empty decks or when nothing is due
+			if m.session == nil {
+				keys = []key.Binding{
+					m.keys.CreateCard,
+				}
+				if m.currentDeck != nil {
+					keys = append(keys, m.keys.Browse)
+				}
+			}
+		} else if m.typing {
+			// Typing an answer
+			keys = []key.Binding{
+				m.keys.Submit,
+			}
+			if m.morse && m.canPlay() {
+				keys = append(keys, m.keys.ReplayMorse)
+			}
+		} else if m.choices != nil && m.choicePicked < 0 {
+			// Unanswered multiple-choice card
 			keys = []key.Binding{
+				m.keys.Choose,
+				m.keys.Flip,
+				m.keys.Next,
+				m.keys.Prev,
+				m.keys.ToggleChoice,
+				m.keys.ToggleMorse,
+				m.keys.ToggleReverse,
+				m.keys.ToggleFormat,
+				m.keys.ToggleShuffle,
+				m.keys.Reshuffle,
+			}
+		} else if m.showAnswer {
+			// Grade the revealed card
+			keys = []key.Binding{
+				m.keys.Again,
+				m.keys.Hard,
+				m.keys.Good,
+				m.keys.Easy,
+				m.keys.Flip,
+				m.keys.Next,
+				m.keys.Prev,
 				m.keys.CreateCard,
+				m.keys.EditCard,
+				m.keys.Browse,
+				m.keys.OpenEditor,
+				m.keys.DeleteCard,
+				m.keys.ToggleChoice,
+				m.keys.ToggleMorse,
+				m.keys.ToggleReverse,
+				m.keys.ToggleFormat,
+				m.keys.ToggleShuffle,
+				m.keys.Reshuffle,
+			}
+			if m.pendingGrade != 0 {
+				// Suggested grade from a multiple-choice pick or typed answer
+				keys = append([]key.Binding{m.keys.Continue}, keys...)
+			}
+			if m.morse && m.canPlay() {
+				keys = append(keys, m.keys.ReplayMorse)
 			}
 		} else {
 			// For decks with cards
@@ -124,9 +375,31 @@ func (m model) getKeysForMode() []key.Binding {
 				m.keys.Next,
 				m.keys.Prev,
 				m.keys.CreateCard,
+				m.keys.EditCard,
+				m.keys.Browse,
+				m.keys.OpenEditor,
 				m.keys.DeleteCard,
+				m.keys.ToggleChoice,
+				m.keys.ToggleMorse,
+				m.keys.ToggleReverse,
+				m.keys.ToggleFormat,
+				m.keys.ToggleShuffle,
+				m.keys.Reshuffle,
 			}
 		}
+
+		if m.session != nil {
+			// Sessions span decks, so actions on a whole deck are left out
+			keys = slices.DeleteFunc(keys, func(b key.Binding) bool {
+				return b.Help() == m.keys.CreateCard.Help() ||
+					b.Help() == m.keys.Browse.Help() ||
+					b.Help() == m.keys.ToggleReverse.Help() ||
+					b.Help() == m.keys.ToggleShuffle.Help() ||
+					b.Help() == m.keys.ToggleChoice.Help() ||
+					b.Help() == m.keys.ToggleFormat.Help() ||
+					b.Help() == m.keys.ToggleMorse.Help()
+			})
+		}
 	case ModeConfirmRemoveCard:
 		keys = []key.Binding{
 			m.keys.Yes,
@@ -141,7 +414,13 @@ func (m model) getKeysForMode() []key.Binding {
 		keys = []key.Binding{
 			confirmEnter,
 		}
-	case ModeCreateCard:
+	case ModeCreateCard, ModeEditCard:
+		keys = []key.Binding{
+			m.keys.NextField,
+			m.keys.Save,
+			m.keys.OpenEditor,
+		}
+	case ModeDeckProperties:
 		confirmEnter := key.NewBinding(
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "confirm"),
@@ -149,6 +428,17 @@ func (m model) getKeysForMode() []key.Binding {
 
 		keys = []key.Binding{
 			confirmEnter,
+			m.keys.DeckShuffle,
+		}
+	case ModeFilteredDeck:
+		saveEnter := key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "next/save"),
+		)
+
+		keys = []key.Binding{
+			m.keys.NextField,
+			saveEnter,
 		}
 	case ModeSettings:
 		keys = []key.Binding{
@@ -156,6 +446,42 @@ func (m model) getKeysForMode() []key.Binding {
 			m.keys.Down,
 			m.keys.Toggle,
 		}
+	case ModeSearch:
+		openEnter := key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open card"),
+		)
+
+		keys = []key.Binding{
+			m.keys.PrevResult,
+			m.keys.NextResult,
+			openEnter,
+		}
+	case ModeBrowseCards:
+		if m.filtering {
+			applyEnter := key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "apply filter"),
+			)
+
+			keys = []key.Binding{
+				applyEnter,
+			}
+		} else {
+			keys = []key.Binding{
+				m.keys.Up,
+				m.keys.Down,
+				m.keys.Study,
+				m.keys.EditCard,
+				m.keys.Filter,
+				m.keys.SortColumn,
+			}
+		}
+	case ModeStats:
+		keys = []key.Binding{
+			m.keys.Next,
+			m.keys.Prev,
+		}
 	case ModeConfirmDelete:
 		confirmEnter := key.NewBinding(
 			key.WithKeys("enter"),
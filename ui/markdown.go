@@ -0,0 +1,136 @@
+// ui/markdown.go
+package ui
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// markdownRenderers caches a renderer per face style, since building one
+// sets up the whole style sheet.
+var markdownRenderers = map[markdownKey]*glamour.TermRenderer{}
+
+// markdownKey is what a renderer depends on: the face's colour and weight
+// and the width text is wrapped at.
+type markdownKey struct {
+	color string
+	bold  bool
+	width int
+}
+
+// RenderFace renders one side of a card in style. The text is treated as
+// Markdown unless plain is set. Single lines are centred like plain faces;
+// anything longer is left-aligned. Fenced code blocks are highlighted in
+// either case.
+func RenderFace(style lipgloss.Style, text string, plain bool) string {
+	segments := splitFences(text)
+	if len(segments) == 1 && !segments[0].code {
+		return renderProse(style, text, plain)
+	}
+
+	blocks := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment.code {
+			blocks = append(blocks, renderCode(segment.lang, segment.text, style.GetWidth()))
+		} else {
+			blocks = append(blocks, renderProse(style, strings.Trim(segment.text, "\n"), plain))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func renderProse(style lipgloss.Style, text string, plain bool) string {
+	if plain {
+		return faceStyle(style, text).Render(text)
+	}
+
+	rendered, err := renderMarkdown(style, text)
+	if err != nil {
+		log.Printf("Error rendering markdown: %v", err)
+		return faceStyle(style, text).Render(text)
+	}
+
+	block := lipgloss.NewStyle().Width(style.GetWidth()).Align(lipgloss.Center)
+	return faceStyle(block, rendered).Render(rendered)
+}
+
+func renderMarkdown(style lipgloss.Style, text string) (string, error) {
+	color, _ := style.GetForeground().(lipgloss.Color)
+	k := markdownKey{string(color), style.GetBold(), style.GetWidth()}
+
+	r, ok := markdownRenderers[k]
+	if !ok {
+		var err error
+		r, err = glamour.NewTermRenderer(
+			glamour.WithStyles(markdownStyle(k.color, k.bold)),
+			glamour.WithWordWrap(k.width),
+			glamour.WithColorProfile(lipgloss.ColorProfile()),
+		)
+		if err != nil {
+			return "", err
+		}
+		markdownRenderers[k] = r
+	}
+
+	out, err := r.Render(text)
+	if err != nil {
+		return "", err
+	}
+
+	// Glamour pads lines to the wrap width and surrounds the document with
+	// blank lines; the card box does its own spacing
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		trimmed := trailingPadding.ReplaceAllString(line, "")
+		if trimmed != line && trimmed != "" {
+			trimmed += "\x1b[0m"
+		}
+		lines = append(lines, trimmed)
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// trailingPadding matches the styled spaces glamour pads lines with.
+var trailingPadding = regexp.MustCompile(`(?:\x1b\[[0-9;]*m| )+$`)
+
+// markdownStyle adapts glamour's dark theme to a card face: no margins, the
+// face's colour for body text, and the app's greens for headings. Strong text
+// matches ClozeFillStyle, which is how cloze fills are highlighted.
+func markdownStyle(color string, bold bool) ansi.StyleConfig {
+	cfg := styles.DarkStyleConfig
+
+	margin := uint(0)
+	cfg.Document.Margin = &margin
+	cfg.Document.BlockPrefix = ""
+	cfg.Document.BlockSuffix = ""
+	if color != "" {
+		cfg.Document.Color = &color
+	}
+	cfg.Document.Bold = &bold
+
+	headingColor := "#6ED5B8"
+	cfg.Heading.Color = &headingColor
+
+	h1Color, h1Background := "#FFFFFF", "#1E735E"
+	cfg.H1.Color = &h1Color
+	cfg.H1.BackgroundColor = &h1Background
+
+	strongColor, on := "#6ED5B8", true
+	cfg.Strong.Color = &strongColor
+	cfg.Strong.Bold = &on
+	cfg.Strong.Underline = &on
+
+	return cfg
+}
@@ -4,16 +4,22 @@ package ui
 import (
 	"fmt"
 	"log"
+	"math/rand"
 	"strings"
+	"time"
 
+	"go-flashcards/audio"
 	"go-flashcards/data"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
 )
 
@@ -28,6 +34,12 @@ const (
 	ModeCreateCard
 	ModeConfirmDelete
 	ModeConfirmRemoveCard
+	ModeStats
+	ModeEditCard
+	ModeDeckProperties
+	ModeBrowseCards
+	ModeSearch
+	ModeFilteredDeck
 )
 
 // model represents the UI state and data
@@ -44,29 +56,145 @@ type model struct {
 	height      int
 	settings    data.Settings
 
+	// settingsCursor is the selected row of the settings screen
+	settingsCursor int
+
+	// Review timing for the card currently on screen
+	cardShownAt time.Time
+	answerTime  time.Duration
+
+	// Study timers: ticking is set while a timer tick is pending. A session
+	// with a time box ends at studyDeadline, setting timeUp.
+	ticking          bool
+	studyStarted     time.Time
+	studyDeadline    time.Time
+	studyReviewed    int
+	studyAnswerTotal time.Duration
+	timeUp           bool
+	timerNotice      string
+
+	// Multiple-choice options of the card on screen, and the grade suggested
+	// by the user's pick
+	choices      []string
+	choiceAnswer int
+	choicePicked int
+	pendingGrade data.Grade
+	rng          *rand.Rand
+
+	// Typed answers: typing is set while the user is entering an answer
+	typedInput  textinput.Model
+	typing      bool
+	answerCheck *data.AnswerResult
+
+	// Audio: sounds are queued during an update and played after it. morse
+	// is set while the card on screen is reviewed as Morse code and
+	// morsePending until its audio has been queued. player is audio.Null
+	// when no audio player is installed.
+	player       audio.Player
+	sounds       [][]byte
+	morse        bool
+	morsePending bool
+	audioNotice  string
+
 	// Deck creation
 	newDeckInput textinput.Model
 
 	// Card creation
-	questionInput textinput.Model
-	answerInput   textinput.Model
+	questionInput textarea.Model
+	answerInput   textarea.Model
 	tagsInput     textinput.Model
 	activeInput   int
 
+	// Card editing reuses the card creation inputs
+	editCardID      uuid.UUID
+	editFromBrowser bool
+
+	// External editor: the error of the last edit, and the file of a
+	// malformed edit kept so it can be fixed
+	editorErr       string
+	editorDraft     string
+	editorDraftDeck uuid.UUID
+	editorDraftCard uuid.UUID
+
 	confirmInput textinput.Model
 	deckToDelete *data.Deck
+
+	// Deck properties: name, description, author, colour and emoji
+	propertyInputs []textinput.Model
+	activeProperty int
+	deckToEdit     *data.Deck
+	propertiesErr  string
+
+	// propertyShuffle is the deck's shuffle default being edited
+	propertyShuffle bool
+
+	// Statistics: 0 is all decks, i > 0 is the i-th deck in list order
+	reviewLog  []data.ReviewEntry
+	statsScope int
+
+	// Card browser: browseIDs holds the card IDs in table row order and
+	// browseFrom the mode it was opened from
+	cardTable    table.Model
+	browseFilter textinput.Model
+	filtering    bool
+	browseSort   int
+	browseDesc   bool
+	browseIDs    []uuid.UUID
+	browseFrom   Mode
+
+	// Search across all decks
+	searchInput   textinput.Model
+	searchResults []data.SearchResult
+	searchCursor  int
+
+	// Study session over items from several decks, e.g. a filtered deck. It
+	// is nil while studying a single deck.
+	session *data.Session
+
+	// Filtered deck editor: name and query
+	filterInputs   []textinput.Model
+	activeFilter   int
+	filterToEdit   uuid.UUID
+	filterErr      string
+	filterToDelete *data.FilteredDeck
 }
 
 type deckItem struct {
+	id          uuid.UUID
+	name        string
+	emoji       string
+	count       int
+	due         int
+	lastStudied time.Time
+}
+
+func (i deckItem) Title() string {
+	if i.emoji != "" {
+		return i.emoji + " " + i.name
+	}
+	return i.name
+}
+func (i deckItem) ID() uuid.UUID       { return i.id }
+func (i deckItem) FilterValue() string { return i.name }
+
+func (i deckItem) Description() string {
+	return fmt.Sprintf("%d cards · %d due · %s", i.count, i.due, formatStudied(i.lastStudied, time.Now()))
+}
+
+// filterItem is a filtered deck in the deck list.
+type filterItem struct {
 	id    uuid.UUID
 	name  string
+	query string
 	count int
 }
 
-func (i deckItem) Title() string       { return i.name }
-func (i deckItem) ID() uuid.UUID       { return i.id }
-func (i deckItem) Description() string { return fmt.Sprintf("%d cards", i.count) }
-func (i deckItem) FilterValue() string { return i.name }
+func (i filterItem) Title() string       { return "🔖 " + i.name }
+func (i filterItem) FilterValue() string { return i.name }
+
+func (i filterItem) Description() string {
+	return fmt.Sprintf("filtered · %d cards · %s", i.count, i.query)
+}
 
 func newTextInput(placeholder string, charLimit int, width int) textinput.Model {
 	ti := textinput.New()
@@ -79,31 +207,74 @@ func newTextInput(placeholder string, charLimit int, width int) textinput.Model
 	return ti
 }
 
+// newTextArea creates a multi-line editor for card faces.
+func newTextArea(placeholder string, charLimit, width, height int) textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = placeholder
+	ta.CharLimit = charLimit
+	ta.ShowLineNumbers = false
+	ta.SetWidth(width)
+	ta.SetHeight(height)
+	ta.Cursor.Style = CursorStyle
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.FocusedStyle.Prompt = PromptStyle
+	ta.BlurredStyle.Prompt = HelpStyle
+
+	return ta
+}
+
 func CreateDeckItems(decks []*data.Deck) []list.Item {
+	now := time.Now()
 	items := make([]list.Item, len(decks))
 	for i, deck := range decks {
 		items[i] = deckItem{
-			id:    deck.ID,
-			name:  deck.Name,
-			count: len(deck.Cards),
+			id:          deck.ID,
+			name:        deck.Name,
+			emoji:       deck.Emoji,
+			count:       len(deck.Cards),
+			due:         deck.DueCount(now),
+			lastStudied: deck.LastStudied(),
 		}
 	}
 	return items
 }
 
-func UpdateDeckList(deckManager *data.DeckManager, listModel *list.Model) {
+// CreateFilterItems lists the filtered decks with the number of items each
+// matches.
+func CreateFilterItems(deckManager *data.DeckManager) []list.Item {
+	now := time.Now()
+	filters := deckManager.GetFilteredDecks()
+	items := make([]list.Item, len(filters))
+	for i, f := range filters {
+		count := 0
+		if q, err := data.ParseQuery(f.Query); err == nil {
+			count = len(deckManager.FilterItems(q, now))
+		}
+		items[i] = filterItem{
+			id:    f.ID,
+			name:  f.Name,
+			query: f.Query,
+			count: count,
+		}
+	}
+	return items
+}
+
+// UpdateDeckList reloads the deck list items. The returned command reapplies
+// an active filter to the new items.
+func UpdateDeckList(deckManager *data.DeckManager, listModel *list.Model) tea.Cmd {
 	decks := deckManager.GetAllDecks()
 	// Update the title to include deck count
 	deckManager.SortDecksAlphabetical(decks)
-	items := CreateDeckItems(decks)
-	listModel.SetItems(items)
+	items := append(CreateDeckItems(decks), CreateFilterItems(deckManager)...)
+	return listModel.SetItems(items)
 }
 
 func NewModel(deckManager *data.DeckManager) model {
 
 	decks := deckManager.GetAllDecks()
 	deckManager.SortDecksAlphabetical(decks)
-	items := CreateDeckItems(decks)
+	items := append(CreateDeckItems(decks), CreateFilterItems(deckManager)...)
 
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = ListSelectedItem
@@ -116,6 +287,8 @@ func NewModel(deckManager *data.DeckManager) model {
 	l.Styles.PaginationStyle = ListItemStyle
 	l.Styles.HelpStyle = HelpStyle
 	l.Styles.NoItems = HelpStyle
+	l.Styles.FilterPrompt = PromptStyle
+	l.Styles.FilterCursor = CursorStyle
 
 	settings, err := data.LoadSettings()
 	if err != nil {
@@ -130,10 +303,25 @@ func NewModel(deckManager *data.DeckManager) model {
 
 	newDeckInput := newTextInput("awesome deck name", 50, 30)
 	newDeckInput.Focus()
-	questionInput := newTextInput("question", 200, 50)
-	answerInput := newTextInput("answer", 200, 50)
+	questionInput := newTextArea("question", 2000, 60, 5)
+	answerInput := newTextArea("answer", 2000, 60, 5)
 	tagsInput := newTextInput("tags (comma-separated)", 100, 50)
 	confirmInput := newTextInput("Type 'delete' to confirm", 10, 30)
+	typedInput := newTextInput("type your answer", 200, 50)
+	browseFilter := newTextInput("filter cards", 100, 40)
+	browseFilter.Prompt = "/ "
+	searchInput := newTextInput("search questions, answers and tags", 100, 50)
+	propertyInputs := []textinput.Model{
+		newTextInput("deck name", 50, 50),
+		newTextInput("what is this deck about?", 200, 50),
+		newTextInput("author", 50, 50),
+		newTextInput("#1E735E or 0-255", 7, 50),
+		newTextInput("emoji", 8, 50),
+	}
+	filterInputs := []textinput.Model{
+		newTextInput("filtered deck name", 50, 50),
+		newTextInput(`tag:networking -tag:easy deck:"Go*" is:due lapses>3`, 200, 60),
+	}
 
 	m := model{
 		mode:          ModeDeckList,
@@ -151,6 +339,15 @@ func NewModel(deckManager *data.DeckManager) model {
 		tagsInput:     tagsInput,
 		activeInput:   0,
 		confirmInput:  confirmInput,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		typedInput:    typedInput,
+		player:        audio.NewPlayer(settings.AudioCommand),
+
+		propertyInputs: propertyInputs,
+		cardTable:      newCardTable(),
+		browseFilter:   browseFilter,
+		searchInput:    searchInput,
+		filterInputs:   filterInputs,
 	}
 	return m
 }
@@ -161,17 +358,64 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+
+	// Keep the study timers ticking while a card is on screen
+	if m.timersActive() && !m.ticking {
+		m.ticking = true
+		cmd = tea.Batch(cmd, tickTimer())
+	}
+
+	// Sounds play in order, followed by the question of a Morse card that
+	// just came on screen
+	if m.morsePending {
+		m.morsePending = false
+		m.sounds = append(m.sounds, m.morseWAV())
+	}
+	if len(m.sounds) > 0 {
+		cmd = tea.Batch(cmd, m.play(m.sounds...))
+		m.sounds = nil
+	}
+	return m, cmd
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case timerTickMsg:
+		if !m.timersActive() {
+			m.ticking = false
+			return m, nil
+		}
+		m.checkTimeLimit(time.Time(msg))
+		return m, tickTimer()
+
+	case audioDoneMsg:
+		if msg.err != nil {
+			log.Printf("Error playing audio: %v", msg.err)
+			m.audioNotice = "Could not play audio"
+		}
+		return m, nil
+
+	case editorFinishedMsg:
+		return m, m.finishEditing(msg)
+
+	case list.FilterMatchesMsg:
+		// The deck list filters in the background
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
 		// Global keybindings
 		switch {
-		case key.Matches(msg, m.keys.Settings) && m.mode == ModeDeckList:
-			m.list.Select(0)
+		case key.Matches(msg, m.keys.Settings) && m.mode == ModeDeckList && !m.isTyping():
+			m.settingsCursor = 0
 			m.mode = ModeSettings
-		case key.Matches(msg, m.keys.Quit) && (m.mode != ModeCreateDeck) && (m.mode != ModeCreateCard):
+		case key.Matches(msg, m.keys.ForceQuit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Quit) && !m.isTyping():
 			return m, tea.Quit
 		}
 
@@ -180,41 +424,158 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case ModeSettings:
 			switch {
 			case key.Matches(msg, m.keys.Up):
-				if m.list.Cursor() > 0 {
-					m.list.Select(m.list.Cursor() - 1)
+				if m.settingsCursor > 0 {
+					m.settingsCursor--
 				}
 			case key.Matches(msg, m.keys.Down):
-				if m.list.Cursor() < 2 {
-					m.list.Select(m.list.Cursor() + 1)
+				if m.settingsCursor < settingsCount-1 {
+					m.settingsCursor++
 				}
 			case key.Matches(msg, m.keys.Toggle):
-				switch m.list.Cursor() {
+				switch m.settingsCursor {
 				case 0:
 					m.settings.ChaosMode = !m.settings.ChaosMode
 				case 1:
 					m.settings.ShowTimer = !m.settings.ShowTimer
 				case 2:
 					m.settings.Audio = !m.settings.Audio
+				case 3:
+					if m.settings.Scheduler == data.SchedulerFSRS {
+						m.settings.Scheduler = data.SchedulerSM2
+					} else {
+						m.settings.Scheduler = data.SchedulerFSRS
+					}
+				case 4:
+					m.settings.TypedAnswer = !m.settings.TypedAnswer
+				case 5:
+					m.settings.CardTimeLimit = nextOption(cardTimeLimits, m.settings.CardTimeLimit)
+				case 6:
+					if m.settings.TimeLimitAction == data.TimeLimitFail {
+						m.settings.TimeLimitAction = data.TimeLimitFlip
+					} else {
+						m.settings.TimeLimitAction = data.TimeLimitFail
+					}
+				case 7:
+					m.settings.SessionMinutes = nextOption(sessionLengths, m.settings.SessionMinutes)
+				case 8:
+					m.settings.Morse.WPM = nextOption(morseSpeeds, m.settings.Morse.WPM)
+				case 9:
+					m.settings.Morse.FarnsworthWPM = nextOption(farnsworthSpeeds, m.settings.Morse.FarnsworthWPM)
 				}
 				if err := data.SaveSettings(m.settings); err != nil {
 					log.Printf("Error saving settings: %v", err)
 				}
 			case key.Matches(msg, m.keys.Back):
 				m.mode = ModeDeckList
-				m.list.Select(0)
+			}
+
+		case ModeStats:
+			scopes := m.deckManager.GetNumDecks() + 1
+			switch {
+			case key.Matches(msg, m.keys.Next):
+				m.statsScope = (m.statsScope + 1) % scopes
+			case key.Matches(msg, m.keys.Prev):
+				m.statsScope = (m.statsScope - 1 + scopes) % scopes
+			case key.Matches(msg, m.keys.Back):
+				m.mode = ModeDeckList
 			}
 
 		case ModeDeckList:
+			if m.list.FilterState() != list.Unfiltered {
+				// While typing a filter every key goes to it; once applied,
+				// esc clears it and the other deck list keys work as usual
+				if m.list.SettingFilter() || key.Matches(msg, m.keys.Back) {
+					m.list, cmd = m.list.Update(msg)
+					cmds = append(cmds, cmd)
+					break
+				}
+			}
+
 			switch {
-			case key.Matches(msg, m.keys.Up, m.keys.Down):
+			case key.Matches(msg, m.keys.Up, m.keys.Down, m.keys.Filter):
 				m.list, cmd = m.list.Update(msg)
 				cmds = append(cmds, cmd)
+			case key.Matches(msg, m.keys.Search):
+				m.mode = ModeSearch
+				m.searchInput.Reset()
+				m.searchInput.Focus()
+				m.searchResults = nil
+				m.searchCursor = 0
+				return m, textinput.Blink
+			case key.Matches(msg, m.keys.Browse):
+				i, ok := m.list.SelectedItem().(deckItem)
+				if ok {
+					m.currentDeck = m.deckManager.GetDeckByID(i.id)
+					if m.currentDeck != nil {
+						m.openBrowser()
+					}
+				}
+			case key.Matches(msg, m.keys.Stats):
+				entries, err := data.LoadReviewLog()
+				if err != nil {
+					log.Printf("Error loading review log: %v", err)
+				}
+				m.reviewLog = entries
+				m.statsScope = 0
+				m.mode = ModeStats
+			case key.Matches(msg, m.keys.NewFilter):
+				m.openFilterEditor(data.FilteredDeck{})
+				return m, textinput.Blink
 			case key.Matches(msg, m.keys.CreateDeck):
 				m.mode = ModeCreateDeck
 				m.newDeckInput.Focus()
 				m.newDeckInput.Reset()
 				return m, textinput.Blink
+			case key.Matches(msg, m.keys.EditDeck):
+				if i, ok := m.list.SelectedItem().(filterItem); ok {
+					if f := m.deckManager.GetFilteredDeck(i.id); f != nil {
+						m.openFilterEditor(*f)
+						return m, textinput.Blink
+					}
+				}
+				i, ok := m.list.SelectedItem().(deckItem)
+				if ok {
+					m.deckToEdit = m.deckManager.GetDeckByID(i.id)
+					if m.deckToEdit != nil {
+						values := []string{
+							m.deckToEdit.Name,
+							m.deckToEdit.Description,
+							m.deckToEdit.Author,
+							m.deckToEdit.Color,
+							m.deckToEdit.Emoji,
+						}
+						for j := range m.propertyInputs {
+							m.propertyInputs[j].SetValue(values[j])
+							m.propertyInputs[j].Blur()
+						}
+						m.propertyShuffle = m.deckToEdit.Shuffle
+						m.activeProperty = 0
+						m.propertyInputs[0].Focus()
+						m.propertiesErr = ""
+						m.mode = ModeDeckProperties
+						return m, textinput.Blink
+					}
+				}
+			case key.Matches(msg, m.keys.CopyDeck):
+				i, ok := m.list.SelectedItem().(deckItem)
+				if ok {
+					if _, err := m.deckManager.DuplicateDeck(i.id); err != nil {
+						log.Printf("Error duplicating deck: %v", err)
+					}
+					cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
+				}
 			case key.Matches(msg, m.keys.DeleteDeck):
+				m.deckToDelete, m.filterToDelete = nil, nil
+				if i, ok := m.list.SelectedItem().(filterItem); ok {
+					m.filterToDelete = m.deckManager.GetFilteredDeck(i.id)
+					if m.filterToDelete != nil {
+						m.mode = ModeConfirmDelete
+						m.confirmInput.Reset()
+						m.confirmInput.Focus()
+						cmds = append(cmds, textinput.Blink)
+					}
+				}
+
 				// Get the selected deck
 				i, ok := m.list.SelectedItem().(deckItem)
 				if ok {
@@ -227,21 +588,40 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			case key.Matches(msg, m.keys.Enter):
+				if i, ok := m.list.SelectedItem().(filterItem); ok {
+					m.studyFilter(i.id)
+					break
+				}
+				if m.settings.ChaosMode {
+					m.studyChaos()
+					break
+				}
 				i, ok := m.list.SelectedItem().(deckItem)
 				if ok {
 					m.currentDeck = m.deckManager.GetDeckByID(i.id)
 					if m.currentDeck != nil {
+						if m.currentDeck.Shuffle && m.currentDeck.ShuffleSeed == 0 {
+							// Decks that shuffle by default get an order the
+							// first time; later visits resume it
+							m.shuffleDeck(m.newSeed())
+						}
+						m.currentDeck.SeekDue(time.Now())
+						m.beginStudy()
 						m.mode = ModeViewCard
-						m.showAnswer = false
+						m.presentCard()
 					}
 				}
 			}
 
 		case ModeViewCard:
-			if m.currentDeck == nil || len(m.currentDeck.Cards) == 0 {
+			if m.nothingToStudy() {
 				switch {
 				case key.Matches(msg, m.keys.Back):
-					m.mode = ModeDeckList
+					cmds = append(cmds, m.leaveStudy())
+				case m.session != nil:
+					// A finished session only goes back
+				case key.Matches(msg, m.keys.Browse) && m.currentDeck != nil:
+					m.openBrowser()
 				case key.Matches(msg, m.keys.CreateCard):
 					m.mode = ModeCreateCard
 					m.questionInput.Reset()
@@ -254,32 +634,114 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 
+			if m.typing {
+				switch {
+				case key.Matches(msg, m.keys.Submit):
+					m.submitTypedAnswer()
+				case key.Matches(msg, m.keys.ReplayMorse) && m.morse:
+					cmds = append(cmds, m.play(m.morseWAV()))
+				case key.Matches(msg, m.keys.Back):
+					cmds = append(cmds, m.leaveStudy())
+				default:
+					m.typedInput, cmd = m.typedInput.Update(msg)
+					cmds = append(cmds, cmd)
+				}
+				break
+			}
+
 			switch {
+			case m.choices != nil && m.choicePicked < 0 && key.Matches(msg, m.keys.Choose):
+				m.pickChoice(int(msg.String()[0] - 'a'))
+			case key.Matches(msg, m.keys.Enter) && m.pendingGrade != 0:
+				m.gradeCurrentCard(m.pendingGrade)
+			case key.Matches(msg, m.keys.ToggleReverse) && m.session == nil:
+				m.currentDeck.Reverse = !m.currentDeck.Reverse
+				if err := m.deckManager.SaveDeckState(m.currentDeck.ID); err != nil {
+					log.Printf("Error saving deck: %v", err)
+				}
+				m.currentDeck.SeekDue(time.Now())
+				m.presentCard()
+			case key.Matches(msg, m.keys.ToggleChoice) && m.session == nil:
+				m.currentDeck.MultipleChoice = !m.currentDeck.MultipleChoice
+				if err := m.deckManager.SaveDeckState(m.currentDeck.ID); err != nil {
+					log.Printf("Error saving deck: %v", err)
+				}
+				m.presentCard()
+			case key.Matches(msg, m.keys.ToggleMorse) && m.session == nil:
+				m.currentDeck.Morse = !m.currentDeck.Morse
+				if err := m.deckManager.SaveDeckState(m.currentDeck.ID); err != nil {
+					log.Printf("Error saving deck: %v", err)
+				}
+				m.presentCard()
+			case key.Matches(msg, m.keys.ReplayMorse) && m.morse:
+				cmds = append(cmds, m.play(m.morseWAV()))
+			case key.Matches(msg, m.keys.ToggleShuffle) && m.session == nil:
+				if m.currentDeck.ShuffleSeed == 0 {
+					m.shuffleDeck(m.newSeed())
+				} else {
+					m.shuffleDeck(0)
+				}
+				m.presentCard()
+			case key.Matches(msg, m.keys.Reshuffle):
+				if m.session != nil {
+					m.session.Shuffle(m.rng)
+				} else {
+					m.shuffleDeck(m.newSeed())
+				}
+				m.presentCard()
+			case key.Matches(msg, m.keys.ToggleFormat) && m.session == nil:
+				m.currentDeck.PlainText = !m.currentDeck.PlainText
+				if err := m.deckManager.SaveDeckState(m.currentDeck.ID); err != nil {
+					log.Printf("Error saving deck: %v", err)
+				}
 			case key.Matches(msg, m.keys.Flip):
-				m.showAnswer = !m.showAnswer
+				m.flipCard()
+			case key.Matches(msg, m.keys.Again) && m.showAnswer:
+				m.gradeCurrentCard(data.GradeAgain)
+			case key.Matches(msg, m.keys.Hard) && m.showAnswer:
+				m.gradeCurrentCard(data.GradeHard)
+			case key.Matches(msg, m.keys.Good) && m.showAnswer:
+				m.gradeCurrentCard(data.GradeGood)
+			case key.Matches(msg, m.keys.Easy) && m.showAnswer:
+				m.gradeCurrentCard(data.GradeEasy)
 			case key.Matches(msg, m.keys.Next):
-				if err := m.currentDeck.NextCard(); err != nil {
+				if m.session != nil {
+					m.session.Next()
+				} else if err := m.currentDeck.NextCard(); err != nil {
 					log.Printf("Error selecting next card: %v", err)
 				}
-				m.showAnswer = false
+				m.presentCard()
 			case key.Matches(msg, m.keys.Prev):
-				if err := m.currentDeck.PrevCard(); err != nil {
+				if m.session != nil {
+					m.session.Prev()
+				} else if err := m.currentDeck.PrevCard(); err != nil {
 					log.Printf("Error selecting previous card: %v", err)
 				}
-				m.showAnswer = false
-			case key.Matches(msg, m.keys.CreateCard):
+				m.presentCard()
+			case key.Matches(msg, m.keys.CreateCard) && m.session == nil:
 				// Switch to card creation mode
 				m.mode = ModeCreateCard
+				m.editorErr = ""
 				m.questionInput.Reset()
 				m.answerInput.Reset()
 				m.tagsInput.Reset()
 				m.questionInput.Focus()
 				m.activeInput = 0
 				return m, textinput.Blink
+			case key.Matches(msg, m.keys.EditCard):
+				// Switch to card editing mode with the current card pre-filled
+				card, _ := m.studyItem()
+				m.editCard(card)
+				return m, textinput.Blink
+			case key.Matches(msg, m.keys.Browse) && m.session == nil:
+				m.openBrowser()
+			case key.Matches(msg, m.keys.OpenEditor):
+				card, _ := m.studyItem()
+				return m, m.openInEditor(card.ID, data.NewCardFile(*card))
 			case key.Matches(msg, m.keys.DeleteCard):
 				m.mode = ModeConfirmRemoveCard
 			case key.Matches(msg, m.keys.Back):
-				m.mode = ModeDeckList
+				cmds = append(cmds, m.leaveStudy())
 			}
 
 		case ModeCreateDeck:
@@ -294,7 +756,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						log.Printf("Error saving deck: %v", err)
 					}
 
-					UpdateDeckList(m.deckManager, &m.list)
+					cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
 					data.SaveDeck(*newDeck)
 
 					m.currentDeck = newDeck
@@ -314,80 +776,69 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.newDeckInput, cmd = m.newDeckInput.Update(msg)
 				cmds = append(cmds, cmd)
 			}
-		case ModeCreateCard:
+		case ModeCreateCard, ModeEditCard:
 			switch {
 			case key.Matches(msg, m.keys.Back):
-				// Cancel card creation and return to viewing the deck
-				m.mode = ModeViewCard
-
-			case key.Matches(msg, m.keys.Enter):
-				switch m.activeInput {
-				case 0: // Question field
-					question := strings.TrimSpace(m.questionInput.Value())
-					if question != "" {
-						// Move to answer field
-						m.activeInput = 1
-						m.questionInput.Blur()
-						m.answerInput.Focus()
-						return m, textinput.Blink
-					}
-					// If empty, just blink
-					return m, textinput.Blink
-
-				case 1: // Answer field
-					answer := strings.TrimSpace(m.answerInput.Value())
-					if answer != "" {
-						// Move to tags field
-						m.activeInput = 2
-						m.answerInput.Blur()
-						m.tagsInput.Focus()
-						return m, textinput.Blink
-					}
-					// If empty, just blink
+				// Cancel card creation or editing
+				m.leaveCardEditor()
+
+			case key.Matches(msg, m.keys.OpenEditor):
+				// Continue in the external editor with what has been typed so far
+				cardID := uuid.Nil
+				if m.mode == ModeEditCard {
+					cardID = m.editCardID
+				}
+				return m, m.openInEditor(cardID, data.CardFile{
+					Question: m.questionInput.Value(),
+					Answer:   m.answerInput.Value(),
+					Tags:     parseTags(m.tagsInput.Value()),
+				})
+
+			case key.Matches(msg, m.keys.Save), key.Matches(msg, m.keys.Enter) && m.activeInput == 2:
+				// Enter adds newlines to the question and answer, so it only
+				// saves from the tags field
+				question := strings.TrimSpace(m.questionInput.Value())
+				answer := strings.TrimSpace(m.answerInput.Value())
+
+				if question == "" || (answer == "" && !data.HasCloze(question)) {
 					return m, textinput.Blink
+				}
 
-				case 2: // Tags field
-					question := strings.TrimSpace(m.questionInput.Value())
-					answer := strings.TrimSpace(m.answerInput.Value())
+				tags := parseTags(m.tagsInput.Value())
 
-					if question == "" || answer == "" {
-						return m, textinput.Blink
+				if m.mode == ModeEditCard {
+					edited := data.NewCard(question, answer, tags)
+					edited.ID = m.editCardID
+					if err := m.deckManager.UpdateCard(m.currentDeck.ID, edited); err != nil {
+						log.Printf("Error updating card: %v", err)
 					}
 
-					tagsStr := strings.TrimSpace(m.tagsInput.Value())
+					m.questionInput.Blur()
+					m.answerInput.Blur()
+					m.tagsInput.Blur()
 
-					// Parse optional tags
-					var tags []string
-					if tagsStr != "" {
-						tags = strings.Split(tagsStr, ",")
-						for i, tag := range tags {
-							tags[i] = strings.TrimSpace(tag)
-						}
-					}
+					m.leaveCardEditor()
+					return m, nil
+				}
 
-					newCard := data.Card{
-						Question: question,
-						Answer:   answer,
-						Tags:     tags,
-					}
+				newCard := data.NewCard(question, answer, tags)
 
-					if err := m.deckManager.AddCardToDeck(m.currentDeck.ID, newCard); err != nil {
-						log.Printf("Error adding card: %v", err)
-					}
+				if err := m.deckManager.AddCardToDeck(m.currentDeck.ID, newCard); err != nil {
+					log.Printf("Error adding card: %v", err)
+				}
 
-					UpdateDeckList(m.deckManager, &m.list)
+				cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
 
-					m.questionInput.Reset()
-					m.answerInput.Reset()
-					m.tagsInput.Reset()
+				m.questionInput.Reset()
+				m.answerInput.Reset()
+				m.tagsInput.Reset()
 
-					m.activeInput = 0
-					m.questionInput.Focus()
-					m.answerInput.Blur()
-					m.tagsInput.Blur()
+				m.activeInput = 0
+				m.questionInput.Focus()
+				m.answerInput.Blur()
+				m.tagsInput.Blur()
 
-					return m, textinput.Blink
-				}
+				return m, textinput.Blink
 
 			case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
 				m.activeInput = (m.activeInput + 1) % 3
@@ -440,18 +891,164 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, cmd)
 			}
 
+		case ModeSearch:
+			switch {
+			case key.Matches(msg, m.keys.PrevResult):
+				if m.searchCursor > 0 {
+					m.searchCursor--
+				}
+			case key.Matches(msg, m.keys.NextResult):
+				if m.searchCursor < len(m.searchResults)-1 {
+					m.searchCursor++
+				}
+			case key.Matches(msg, m.keys.Enter):
+				// Jump to the selected card, due or not
+				if m.searchCursor < len(m.searchResults) {
+					result := m.searchResults[m.searchCursor]
+					m.searchInput.Blur()
+					m.studyCard(result.Deck, result.Card.ID, result.Deck.ItemKeys(result.Card)[0])
+				}
+			case key.Matches(msg, m.keys.Back):
+				m.searchInput.Blur()
+				m.mode = ModeDeckList
+			default:
+				m.searchInput, cmd = m.searchInput.Update(msg)
+				cmds = append(cmds, cmd)
+				m.searchResults = m.deckManager.Search(m.searchInput.Value())
+				m.searchCursor = 0
+			}
+
+		case ModeBrowseCards:
+			if m.filtering {
+				switch {
+				case key.Matches(msg, m.keys.Enter):
+					m.filtering = false
+					m.browseFilter.Blur()
+				case key.Matches(msg, m.keys.Back):
+					m.filtering = false
+					m.browseFilter.Blur()
+					m.browseFilter.Reset()
+					m.refreshBrowser()
+				default:
+					m.browseFilter, cmd = m.browseFilter.Update(msg)
+					cmds = append(cmds, cmd)
+					m.refreshBrowser()
+				}
+				break
+			}
+
+			switch {
+			case key.Matches(msg, m.keys.Filter):
+				m.filtering = true
+				m.browseFilter.Focus()
+				return m, textinput.Blink
+			case key.Matches(msg, m.keys.SortColumn):
+				column := int(msg.String()[0] - '1')
+				if column == m.browseSort {
+					m.browseDesc = !m.browseDesc
+				} else {
+					m.browseSort = column
+					m.browseDesc = false
+				}
+				m.refreshBrowser()
+			case key.Matches(msg, m.keys.Study):
+				// Study the selected card, due or not
+				if card := m.browsedCard(); card != nil {
+					m.studyCard(m.currentDeck, card.ID, m.currentDeck.ItemKeys(card)[0])
+				}
+			case key.Matches(msg, m.keys.EditCard):
+				if card := m.selectBrowsedCard(); card != nil {
+					m.editCard(card)
+					m.editFromBrowser = true
+					return m, textinput.Blink
+				}
+			case key.Matches(msg, m.keys.Back):
+				cmds = append(cmds, m.leaveBrowser())
+			default:
+				m.cardTable, cmd = m.cardTable.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+
+		case ModeDeckProperties:
+			switch {
+			case key.Matches(msg, m.keys.Back):
+				m.mode = ModeDeckList
+			case key.Matches(msg, m.keys.Enter):
+				if m.activeProperty < len(m.propertyInputs)-1 {
+					m.focusProperty(m.activeProperty + 1)
+					return m, textinput.Blink
+				}
+
+				info := data.DeckInfo{
+					Name:        m.propertyInputs[0].Value(),
+					Description: m.propertyInputs[1].Value(),
+					Author:      m.propertyInputs[2].Value(),
+					Color:       m.propertyInputs[3].Value(),
+					Emoji:       m.propertyInputs[4].Value(),
+					Shuffle:     m.propertyShuffle,
+				}
+				if err := m.deckManager.UpdateDeckInfo(m.deckToEdit.ID, info); err != nil {
+					m.propertiesErr = err.Error()
+					return m, nil
+				}
+
+				cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
+				m.mode = ModeDeckList
+			case key.Matches(msg, m.keys.DeckShuffle):
+				m.propertyShuffle = !m.propertyShuffle
+			case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
+				m.focusProperty((m.activeProperty + 1) % len(m.propertyInputs))
+				return m, textinput.Blink
+			case key.Matches(msg, key.NewBinding(key.WithKeys("shift+tab"))):
+				m.focusProperty((m.activeProperty - 1 + len(m.propertyInputs)) % len(m.propertyInputs))
+				return m, textinput.Blink
+			default:
+				m.propertyInputs[m.activeProperty], cmd = m.propertyInputs[m.activeProperty].Update(msg)
+				cmds = append(cmds, cmd)
+			}
+
+		case ModeFilteredDeck:
+			switch {
+			case key.Matches(msg, m.keys.Back):
+				m.mode = ModeDeckList
+			case key.Matches(msg, m.keys.Enter):
+				if m.activeFilter == 0 {
+					m.focusFilterInput(1)
+					return m, textinput.Blink
+				}
+				if m.saveFilter() {
+					cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
+					m.mode = ModeDeckList
+				}
+			case key.Matches(msg, key.NewBinding(key.WithKeys("tab", "shift+tab"))):
+				m.focusFilterInput(1 - m.activeFilter)
+				return m, textinput.Blink
+			default:
+				m.filterInputs[m.activeFilter], cmd = m.filterInputs[m.activeFilter].Update(msg)
+				cmds = append(cmds, cmd)
+				m.filterErr = ""
+			}
+
 		case ModeConfirmDelete:
 			switch {
 			case key.Matches(msg, m.keys.Back):
 				m.mode = ModeDeckList
 			case key.Matches(msg, m.keys.Enter):
 				if strings.TrimSpace(m.confirmInput.Value()) == "delete" {
+					if m.filterToDelete != nil {
+						if err := m.deckManager.RemoveFilteredDeck(m.filterToDelete.ID); err != nil {
+							log.Printf("Error deleting filtered deck: %v", err)
+						}
+						m.filterToDelete = nil
+
+						cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
+					}
 					if m.deckToDelete != nil {
 						if err := m.deckManager.RemoveDeck(m.deckToDelete.ID); err != nil {
 							log.Printf("Error deleting deck: %v", err)
 						}
 
-						UpdateDeckList(m.deckManager, &m.list)
+						cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
 
 						if m.list.Cursor() > 0 {
 							m.list.Select(m.list.Cursor() - 1)
@@ -467,14 +1064,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch {
 			case key.Matches(msg, m.keys.Yes), key.Matches(msg, key.NewBinding(key.WithKeys("y"))):
 				// User confirmed card deletion
-				if m.currentDeck != nil && len(m.currentDeck.Cards) > 0 {
+				if m.session != nil {
+					if card, _ := m.studyItem(); card != nil {
+						cardID := card.ID
+						if err := m.deckManager.RemoveCardFromDeck(m.currentDeck.ID, cardID); err != nil {
+							log.Printf("Error removing card: %v", err)
+						}
+						m.session.RemoveCard(cardID)
+					}
+					m.mode = ModeViewCard
+					m.presentCard()
+				} else if m.currentDeck != nil && len(m.currentDeck.Cards) > 0 {
 
 					currentIndex := m.currentDeck.CurrentID
-					if err := m.deckManager.RemoveCardFromDeck(m.currentDeck.ID, currentIndex); err != nil {
+					cardID := m.currentDeck.CurrentCard().ID
+					if err := m.deckManager.RemoveCardFromDeck(m.currentDeck.ID, cardID); err != nil {
 						log.Printf("Error removing card: %v", err)
 					}
 
-					UpdateDeckList(m.deckManager, &m.list)
+					cmds = append(cmds, UpdateDeckList(m.deckManager, &m.list))
 
 					// If we removed the last card, we might need to adjust the current ID
 					if len(m.currentDeck.Cards) == 0 {
@@ -484,12 +1092,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						// We removed the last card in the deck, adjust the current ID
 						m.currentDeck.CurrentID = len(m.currentDeck.Cards) - 1
 						m.mode = ModeViewCard
-						m.showAnswer = false
 					} else {
 						// Just removed a card, stay on the same index (which now points to the next card)
 						m.mode = ModeViewCard
-						m.showAnswer = false
 					}
+					m.currentDeck.SeekDue(time.Now())
+					m.presentCard()
 				}
 
 			case key.Matches(msg, m.keys.No), key.Matches(msg, key.NewBinding(key.WithKeys("n"))), key.Matches(msg, m.keys.Back):
@@ -510,14 +1118,295 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.Height = msg.Height - 8
 
 		m.help.Width = msg.Width - h
+
+		m.cardTable.SetHeight(msg.Height - 14)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// focusProperty moves the focus to the i-th deck property input.
+func (m *model) focusProperty(i int) {
+	m.propertyInputs[m.activeProperty].Blur()
+	m.activeProperty = i
+	m.propertyInputs[i].Focus()
+}
+
+// editCard opens card in the card editor.
+func (m *model) editCard(card *data.Card) {
+	m.mode = ModeEditCard
+	m.editCardID = card.ID
+	m.editFromBrowser = false
+	m.editorErr = ""
+	m.questionInput.SetValue(card.Question)
+	m.answerInput.SetValue(card.Answer)
+	m.tagsInput.SetValue(strings.Join(card.Tags, ", "))
+	m.questionInput.Focus()
+	m.answerInput.Blur()
+	m.tagsInput.Blur()
+	m.activeInput = 0
+}
+
+// leaveCardEditor returns from card creation or editing to the card browser
+// if that is where editing started, or to studying the deck.
+func (m *model) leaveCardEditor() {
+	if m.editFromBrowser {
+		m.editFromBrowser = false
+		m.mode = ModeBrowseCards
+		m.refreshBrowser()
+		return
+	}
+
+	if m.session == nil {
+		m.currentDeck.SeekDue(time.Now())
+	}
+	m.mode = ModeViewCard
+	m.presentCard()
+}
+
+// newSeed returns a random, non-zero shuffle seed.
+func (m *model) newSeed() int64 {
+	for {
+		if seed := m.rng.Int63(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// shuffleDeck orders the current deck by the permutation of seed, or by card
+// order if it is zero, and saves the order so the session can be resumed.
+func (m *model) shuffleDeck(seed int64) {
+	m.currentDeck.Reshuffle(seed, time.Now())
+	if err := m.deckManager.SaveDeckState(m.currentDeck.ID); err != nil {
+		log.Printf("Error saving deck: %v", err)
+	}
+}
+
+// parseTags splits a comma-separated tag list, dropping empty tags.
+func parseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// presentCard shows the front of the current card and starts timing the answer.
+func (m *model) presentCard() {
+	m.showAnswer = false
+	m.editorErr = ""
+	m.timerNotice = ""
+	m.audioNotice = ""
+	m.cardShownAt = time.Now()
+	m.answerTime = 0
+	m.pendingGrade = 0
+
+	m.choices = nil
+	m.choicePicked = -1
+	if m.session != nil {
+		// Deck settings and card actions apply to the deck the item is from
+		if item, ok := m.session.Current(); ok {
+			m.currentDeck = item.Deck
+		}
+	}
+	if m.currentDeck == nil {
+		return
+	}
+	// Only the forward direction plays the question as Morse code, and only
+	// while there is a card to study. Options are drawn from answers, so
+	// the forward direction is also the only one reviewed as multiple
+	// choice.
+	card, itemKey := m.studyItem()
+	m.morse = itemKey != data.ReverseKey && m.currentDeck.IsMorse(card) && !m.nothingToStudy()
+	m.morsePending = m.morse
+	if itemKey != data.ReverseKey && !m.morse && m.currentDeck.IsMultipleChoice(card) {
+		// A deck with too few other answers leaves nothing to pick between,
+		// so the card is reviewed normally
+		if choices, answer := m.currentDeck.Choices(card, m.rng); len(choices) >= 2 {
+			m.choices, m.choiceAnswer = choices, answer
+			m.keys.Choose = choiceKeys(len(choices))
+		}
+	}
+
+	// Morse cards are always answered by typing what was heard. Nothing is
+	// typed while there is nothing to study, so quitting still works.
+	m.answerCheck = nil
+	m.typing = (m.settings.TypedAnswer || m.morse) && card != nil && m.choices == nil &&
+		!m.nothingToStudy()
+	if m.typing {
+		m.typedInput.Reset()
+		m.typedInput.Focus()
+	} else {
+		m.typedInput.Blur()
+	}
+}
+
+// isTyping reports whether keys go to a text input: a form, the typed
+// answer, the search box, or the card browser's or deck list's filter.
+func (m model) isTyping() bool {
+	switch m.mode {
+	case ModeCreateDeck, ModeCreateCard, ModeEditCard, ModeDeckProperties, ModeFilteredDeck:
+		return true
+	}
+	return (m.mode == ModeViewCard && m.typing) ||
+		(m.mode == ModeBrowseCards && m.filtering) ||
+		m.mode == ModeSearch ||
+		(m.mode == ModeDeckList && m.list.SettingFilter())
+}
+
+// expectedAnswer returns the answer of the current review item: the card's
+// answer, its question when reviewed in reverse or as Morse code, or the
+// hidden text of the current cloze.
+func (m model) expectedAnswer() string {
+	card, itemKey := m.studyItem()
+	if card == nil {
+		return ""
+	}
+	if m.morse {
+		return data.MorseText(card.Question)
+	}
+	if card.IsCloze() {
+		return data.ClozeAnswer(card.Question, data.ClozeIndex(itemKey))
+	}
+	if itemKey == data.ReverseKey {
+		return card.Question
+	}
+	return card.Answer
+}
+
+// submitTypedAnswer checks the typed answer, reveals the card and suggests a
+// grade. An empty answer just reveals the card.
+func (m *model) submitTypedAnswer() {
+	m.typing = false
+	m.typedInput.Blur()
+
+	if typed := strings.TrimSpace(m.typedInput.Value()); typed != "" {
+		n := m.settings.AnswerNormalization
+		if m.morse {
+			// Morse code has no case
+			n.IgnoreCase = true
+		}
+		result := data.CheckAnswer(typed, m.expectedAnswer(), n)
+		m.answerCheck = &result
+		m.pendingGrade = result.Grade()
+		m.playFeedback(m.pendingGrade)
+	}
+
+	if !m.showAnswer {
+		m.flipCard()
+	}
+}
+
+// pickChoice answers a multiple-choice card, reveals the result and suggests
+// a grade: good for the right option, again for a wrong one.
+func (m *model) pickChoice(index int) {
+	if index < 0 || index >= len(m.choices) {
+		return
+	}
+
+	m.choicePicked = index
+	if !m.showAnswer {
+		m.flipCard()
+	}
+
+	if index == m.choiceAnswer {
+		m.pendingGrade = data.GradeGood
+	} else {
+		m.pendingGrade = data.GradeAgain
+	}
+	m.playFeedback(m.pendingGrade)
+}
+
+// flipCard toggles the answer. The first reveal of a card stops the answer
+// timer and is recorded in the review log.
+func (m *model) flipCard() {
+	m.showAnswer = !m.showAnswer
+	if !m.showAnswer || m.answerTime != 0 {
+		return
+	}
+
+	now := time.Now()
+	m.answerTime = now.Sub(m.cardShownAt)
+	card, itemKey := m.studyItem()
+	if err := m.currentDeck.RecordItemFlip(card, itemKey, now, m.answerTime); err != nil {
+		log.Printf("Error recording flip: %v", err)
+	}
+}
+
+// gradeCurrentCard records a grade for the current card and advances the
+// session to the next due card.
+func (m *model) gradeCurrentCard(grade data.Grade) {
+	now := time.Now()
+	answerTime := m.answerTime
+	if answerTime == 0 {
+		answerTime = now.Sub(m.cardShownAt)
+	}
+
+	if m.pendingGrade == 0 {
+		// Typed answers and picks were judged when they were given
+		m.playFeedback(grade)
+	}
+
+	scheduler := m.currentDeck.SchedulerFor(m.settings.Scheduler)
+	if m.session != nil {
+		card, itemKey := m.studyItem()
+		if err := m.currentDeck.GradeItem(scheduler, card, itemKey, grade, now, answerTime); err != nil {
+			log.Printf("Error grading card: %v", err)
+		}
+		m.session.Answer(grade)
+	} else if err := m.currentDeck.GradeCurrent(scheduler, grade, now, answerTime); err != nil {
+		log.Printf("Error grading card: %v", err)
+	}
+
+	m.studyReviewed++
+	m.studyAnswerTotal += answerTime
+	if !m.studyDeadline.IsZero() && !now.Before(m.studyDeadline) {
+		// The time box is over once the card on screen is done
+		m.timeUp = true
+	}
+	m.presentCard()
+
+	if m.nothingToStudy() {
+		m.playEffect(audio.EffectComplete)
+	}
+}
+
 func formatBoolSetting(b bool) string {
 	if b {
 		return SettingOnStyle
 	}
 	return SettingOffStyle
 }
+
+func formatSchedulerSetting(name string) string {
+	if name == data.SchedulerFSRS {
+		return SettingValueStyle.Render("FSRS")
+	}
+	return SettingValueStyle.Render("SM-2")
+}
+
+// formatAmountSetting formats a number with its unit, or OFF for zero.
+func formatAmountSetting(n int, unit string) string {
+	if n == 0 {
+		return SettingOffStyle
+	}
+	return SettingValueStyle.Render(fmt.Sprintf("%d%s", n, unit))
+}
+
+// formatAudioSetting shows the sound effects setting, warning when they are
+// on but there is nothing to play them with.
+func formatAudioSetting(on, canPlay bool) string {
+	if on && !canPlay {
+		return formatBoolSetting(on) + HelpStyle.Render(" (no player found)")
+	}
+	return formatBoolSetting(on)
+}
+
+func formatTimeLimitAction(action string) string {
+	if action == data.TimeLimitFail {
+		return SettingValueStyle.Render("Fail")
+	}
+	return SettingValueStyle.Render("Flip")
+}
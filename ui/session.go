@@ -0,0 +1,138 @@
+// ui/session.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"go-flashcards/data"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+)
+
+// startSession studies the items of a session, which may come from several
+// decks.
+func (m *model) startSession(s *data.Session) {
+	m.session = s
+	m.beginStudy()
+	m.mode = ModeViewCard
+	m.presentCard()
+}
+
+// studyCard studies one review item of a card whether it is due or not. It
+// runs as a single-item session, so the deck's current card stays put.
+func (m *model) studyCard(deck *data.Deck, cardID uuid.UUID, key string) {
+	items := []data.SessionItem{{Deck: deck, CardID: cardID, Key: key}}
+	m.startSession(data.NewSession(deck.Name, items))
+}
+
+// leaveStudy returns from studying a deck or a session to the deck list.
+func (m *model) leaveStudy() tea.Cmd {
+	m.typing = false
+	m.session = nil
+	m.studyDeadline = time.Time{}
+	m.timeUp = false
+	m.mode = ModeDeckList
+	return UpdateDeckList(m.deckManager, &m.list)
+}
+
+// studyItem returns the card on screen and the key of its review item: the
+// current item of the session, or the current card of the current deck.
+func (m model) studyItem() (*data.Card, string) {
+	if m.session != nil {
+		item, ok := m.session.Current()
+		if !ok {
+			return nil, ""
+		}
+		return item.Card(), item.Key
+	}
+
+	if m.currentDeck == nil {
+		return nil, ""
+	}
+	return m.currentDeck.CurrentCard(), m.currentDeck.CurrentItemKey()
+}
+
+// nothingToStudy reports whether there is no card to show: the deck is empty
+// or has nothing due, or the session or its time box is over.
+func (m model) nothingToStudy() bool {
+	if m.timeUp {
+		return true
+	}
+	if m.session != nil {
+		return m.session.Remaining() == 0
+	}
+	return m.currentDeck == nil || m.currentDeck.DueCount(time.Now()) == 0
+}
+
+// openFilterEditor opens the filtered deck editor, filled in with f when
+// editing an existing filtered deck.
+func (m *model) openFilterEditor(f data.FilteredDeck) {
+	m.mode = ModeFilteredDeck
+	m.filterToEdit = f.ID
+	m.filterErr = ""
+	m.filterInputs[0].SetValue(f.Name)
+	m.filterInputs[1].SetValue(f.Query)
+	m.focusFilterInput(0)
+}
+
+// focusFilterInput moves the focus to the i-th filtered deck input.
+func (m *model) focusFilterInput(i int) {
+	m.filterInputs[m.activeFilter].Blur()
+	m.activeFilter = i
+	m.filterInputs[i].Focus()
+}
+
+// saveFilter stores the filtered deck being edited. It reports whether it
+// was saved; otherwise filterErr says why not.
+func (m *model) saveFilter() bool {
+	_, err := m.deckManager.SaveFilteredDeck(data.FilteredDeck{
+		ID:    m.filterToEdit,
+		Name:  m.filterInputs[0].Value(),
+		Query: m.filterInputs[1].Value(),
+	})
+	if err != nil {
+		m.filterErr = err.Error()
+		return false
+	}
+	return true
+}
+
+// studyFilter starts a session over the items a filtered deck matches now.
+func (m *model) studyFilter(id uuid.UUID) {
+	f := m.deckManager.GetFilteredDeck(id)
+	if f == nil {
+		return
+	}
+
+	session, err := m.deckManager.StudyFiltered(*f, time.Now())
+	if err != nil {
+		log.Printf("Error starting filtered deck: %v", err)
+		return
+	}
+	session.Name = "🔖 " + session.Name
+	m.startSession(session)
+}
+
+// studyChaos starts a session over the due cards of every deck, shuffled.
+// The cards are graded in their own decks, whose current cards stay put.
+func (m *model) studyChaos() {
+	m.startSession(m.deckManager.ChaosSession("🎲 Chaos mode", time.Now(), m.rng))
+}
+
+// filterPreview describes what the query being edited matches, or why it is
+// invalid.
+func (m model) filterPreview() (string, error) {
+	q, err := data.ParseQuery(m.filterInputs[1].Value())
+	if err != nil {
+		return "", err
+	}
+
+	count := len(m.deckManager.FilterItems(q, time.Now()))
+	if count == 1 {
+		return "1 card matches", nil
+	}
+	return fmt.Sprintf("%d cards match", count), nil
+}
@@ -4,6 +4,9 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"go-flashcards/data"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -40,6 +43,63 @@ var (
 			Align(lipgloss.Center).
 			Width(65)
 
+	TableHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#6ED5B8")).
+				BorderStyle(lipgloss.NormalBorder()).
+				BorderForeground(lipgloss.Color("#1C7A61")).
+				BorderBottom(true).
+				Padding(0, 1)
+
+	TableSelectedStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#1E735E"))
+
+	SearchHighlightStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1A1A1A")).
+				Background(lipgloss.Color("#6ED5B8")).
+				Bold(true)
+
+	CodeBlockStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(lipgloss.Color("#1C7A61")).
+			PaddingLeft(1)
+
+	ClozeFillStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6ED5B8")).
+			Bold(true).
+			Underline(true)
+
+	MorseCodeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#33C4A0")).
+			Bold(true).
+			Align(lipgloss.Center).
+			Width(65)
+
+	ChoiceStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Width(50)
+
+	ChoiceCorrectStyle = ChoiceStyle.
+				Foreground(lipgloss.Color("#7EBC39")).
+				Bold(true)
+
+	ChoiceWrongStyle = ChoiceStyle.
+				Foreground(lipgloss.Color("#FF5F87")).
+				Bold(true)
+
+	DiffEqualStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7EBC39"))
+
+	DiffMissingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F2C94C")).
+				Underline(true)
+
+	DiffExtraStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F87")).
+			Strikethrough(true)
+
 	TagStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#555555")).
 			Italic(false).
@@ -52,6 +112,10 @@ var (
 			Foreground(lipgloss.Color("#888888")).
 			Align(lipgloss.Right)
 
+	TimerWarningStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5F87")).
+				Bold(true)
+
 	ListStyle = lipgloss.NewStyle().
 			Margin(1, 0)
 
@@ -104,7 +168,7 @@ var (
 				BorderForeground(lipgloss.Color("#1C7A61")).
 				Padding(0, 0, 0, 1).
 				MarginLeft(2).
-				Width(30)
+				Width(40)
 
 	SettingOnStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#7EBC39")).
@@ -116,6 +180,10 @@ var (
 			Bold(true).
 			Render("OFF")
 
+	SettingValueStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#6ED5B8")).
+				Bold(true)
+
 	RedMessageStyle = lipgloss.NewStyle().
 			Align(lipgloss.Center).
 			MarginTop(1).
@@ -134,23 +202,216 @@ var (
 			Foreground(lipgloss.Color("#7EBC39")).
 			Render("[n] No")
 
+	StatsContainer = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#1C7A61")).
+			Padding(0, 1).
+			MarginLeft(2).
+			Width(70)
+
+	StatLabelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#AAAAAA"))
+
+	StatValueStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true)
+
+	ForecastStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#2CA889"))
+
+	// Heatmap cell colours from no reviews to the busiest days
+	HeatmapLevels = []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#333333")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#1E735E")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#2CA889")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#33C4A0")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#6ED5B8")),
+	}
+
 	Instructions = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#888888")).
 			Align(lipgloss.Center).
 			Width(60)
 )
 
-// GetCardCounterView renders the card counter.
-func CardCounterView(current, total int) string {
-	counter := fmt.Sprintf("Card %d of %d", current, total)
+// GetCardCounterView renders the card counter along with the number of due cards.
+func CardCounterView(current, total, due int) string {
+	counter := fmt.Sprintf("Card %d of %d · %d due", current, total, due)
 	return CounterStyle.Render(counter)
 }
 
+// SessionCounterView renders the progress of a study session.
+func SessionCounterView(s *data.Session) string {
+	done := s.Total - s.Remaining()
+	counter := fmt.Sprintf("%s · %d of %d done · %d left", s.Name, done, s.Total, s.Remaining())
+	return CounterStyle.Render(counter)
+}
+
+// TimerView renders the stopwatch of the card on screen, with its time limit
+// if there is one, and the time left in a time-boxed session. Parts that are
+// off are zero; the stopwatch turns red in the last quarter of the limit.
+func TimerView(elapsed, limit, left time.Duration, stopwatch, timeBox bool) string {
+	var parts []string
+
+	if stopwatch {
+		clock := "⏱ " + formatClock(elapsed)
+		if limit > 0 {
+			clock += " / " + formatClock(limit)
+		}
+		if limit > 0 && elapsed >= limit*3/4 {
+			parts = append(parts, TimerWarningStyle.Render(clock))
+		} else {
+			parts = append(parts, CounterStyle.Render(clock))
+		}
+	}
+
+	if timeBox {
+		if left > 0 {
+			parts = append(parts, CounterStyle.Render("⌛ "+formatClock(left)+" left"))
+		} else {
+			parts = append(parts, TimerWarningStyle.Render("⌛ time's up after this card"))
+		}
+	}
+
+	return strings.Join(parts, CounterStyle.Render(" · "))
+}
+
+// DeckTitleView renders a deck's name with its emoji, on its own colour if
+// it has one.
+func DeckTitleView(deck *data.Deck) string {
+	style := TitleStyle
+	if deck.Color != "" {
+		style = style.Background(lipgloss.Color(deck.Color))
+	}
+
+	name := deck.Name
+	if deck.Emoji != "" {
+		name = deck.Emoji + " " + name
+	}
+	return style.Render(name)
+}
+
+// StatLine renders a single "label: value" row of the statistics screen.
+func StatLine(label, value string) string {
+	return StatLabelStyle.Render(fmt.Sprintf("%-22s", label+":")) + StatValueStyle.Render(value)
+}
+
+// ForecastView renders daily counts as a one-line bar chart, scaled to the
+// largest count.
+func ForecastView(counts []int) string {
+	bars := []rune(" ▁▂▃▄▅▆▇█")
+
+	peak := 0
+	for _, c := range counts {
+		if c > peak {
+			peak = c
+		}
+	}
+
+	var chart strings.Builder
+	for _, c := range counts {
+		level := 0
+		if peak > 0 && c > 0 {
+			level = 1 + c*(len(bars)-2)/peak
+		}
+		chart.WriteRune(bars[level])
+		chart.WriteRune(bars[level])
+	}
+
+	last := fmt.Sprintf("+%dd", len(counts)-1)
+	axis := fmt.Sprintf("%-*s%s", len(counts)*2-len(last), "today", last)
+	return ForecastStyle.Render(chart.String()) + "\n" + StatLabelStyle.Render(axis) +
+		StatLabelStyle.Render(fmt.Sprintf("  peak %d", peak))
+}
+
+// HeatmapView renders a calendar of reviews per day over the last year, one
+// column per week and one row per weekday, like a GitHub contribution graph.
+func HeatmapView(daily map[time.Time]int, today time.Time) string {
+	const weeks = 53
+
+	start := data.StartOfWeek(today).AddDate(0, 0, -(weeks-1)*7)
+
+	peak := 0
+	for day, count := range daily {
+		if !day.Before(start) && count > peak {
+			peak = count
+		}
+	}
+
+	// Month labels above the first week of each month
+	months := []rune(strings.Repeat(" ", weeks+3))
+	lastMonth := time.Month(0)
+	free := 0
+	for w := 0; w < weeks; w++ {
+		month := start.AddDate(0, 0, w*7).Month()
+		if month != lastMonth && w >= free {
+			copy(months[w:], []rune(month.String()[:3]))
+			free = w + 4
+		}
+		lastMonth = month
+	}
+
+	dayLabels := []string{"Mon", "", "Wed", "", "Fri", "", "Sun"}
+
+	rows := []string{StatLabelStyle.Render("    " + strings.TrimRight(string(months), " "))}
+	for weekday := 0; weekday < 7; weekday++ {
+		var row strings.Builder
+		row.WriteString(StatLabelStyle.Render(fmt.Sprintf("%-4s", dayLabels[weekday])))
+
+		for w := 0; w < weeks; w++ {
+			day := start.AddDate(0, 0, w*7+weekday)
+			if day.After(today) {
+				row.WriteString(" ")
+				continue
+			}
+
+			level := 0
+			if count := daily[day]; count > 0 && peak > 0 {
+				level = 1 + (count*(len(HeatmapLevels)-1)-1)/peak
+			}
+			row.WriteString(HeatmapLevels[level].Render("■"))
+		}
+		rows = append(rows, row.String())
+	}
+
+	var legend strings.Builder
+	legend.WriteString(StatLabelStyle.Render("    Less "))
+	for _, style := range HeatmapLevels {
+		legend.WriteString(style.Render("■"))
+	}
+	legend.WriteString(StatLabelStyle.Render(" More"))
+	rows = append(rows, legend.String())
+
+	return strings.Join(rows, "\n")
+}
+
+// cardView draws the card box around content, widening it for content that
+// must not wrap, such as long code lines.
+func cardView(content string) string {
+	style := CardStyle
+	if w := lipgloss.Width(content) + style.GetHorizontalPadding(); w > style.GetWidth() {
+		style = style.Width(w)
+	}
+	return style.Render(content)
+}
+
+// faceStyle left-aligns multi-line card text such as lists and snippets,
+// which are unreadable when every line is centred.
+func faceStyle(style lipgloss.Style, text string) lipgloss.Style {
+	if strings.Contains(text, "\n") {
+		return style.Align(lipgloss.Left)
+	}
+	return style
+}
+
 // GetCardContentView renders the flashcard content (question, answer, and tags).
+// The question and answer are already rendered with RenderFace.
 func CardContentView(question, answer string, tags []string, showAnswer bool) string {
-	content := QuestionStyle.Render(question)
+	content := question
 	if showAnswer {
-		content += "\n\n" + AnswerStyle.Render(answer)
+		if answer != "" {
+			content += "\n\n" + answer
+		}
 	} else {
 		content += "\n\n" + lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#888888")).
@@ -162,5 +423,86 @@ func CardContentView(question, answer string, tags []string, showAnswer bool) st
 		content += "\n\n" + TagStyle.Render(tagText)
 	}
 
-	return CardStyle.Render(content)
+	return cardView(content)
+}
+
+// ChoiceContentView renders a multiple-choice card with lettered options.
+// Once revealed, the correct option and a wrong pick (picked >= 0) are marked.
+func ChoiceContentView(question string, choices []string, correct, picked int, tags []string, showAnswer bool) string {
+	options := make([]string, len(choices))
+	for i, choice := range choices {
+		option := fmt.Sprintf("%c) %s", 'a'+i, choice)
+
+		switch {
+		case showAnswer && i == correct:
+			options[i] = ChoiceCorrectStyle.Render(option + " ✓")
+		case showAnswer && i == picked:
+			options[i] = ChoiceWrongStyle.Render(option + " ✗")
+		default:
+			options[i] = ChoiceStyle.Render(option)
+		}
+	}
+
+	content := question + "\n\n" +
+		lipgloss.JoinVertical(lipgloss.Left, options...)
+
+	if len(tags) > 0 {
+		tagText := "Tags: " + strings.Join(tags, ", ")
+		content += "\n\n" + TagStyle.Render(tagText)
+	}
+
+	return cardView(content)
+}
+
+// TypedContentView renders the front of a card with an input for the answer.
+func TypedContentView(question, input string, tags []string) string {
+	content := question + "\n\n" + input
+
+	if len(tags) > 0 {
+		tagText := "Tags: " + strings.Join(tags, ", ")
+		content += "\n\n" + TagStyle.Render(tagText)
+	}
+
+	return cardView(content)
+}
+
+// AnswerDiffView renders a typed answer against the expected one: matching
+// characters in green, missing ones underlined and extra ones struck through.
+func AnswerDiffView(result data.AnswerResult) string {
+	var diff strings.Builder
+	for _, segment := range result.Diff {
+		switch segment.Op {
+		case data.DiffEqual:
+			diff.WriteString(DiffEqualStyle.Render(segment.Text))
+		case data.DiffMissing:
+			diff.WriteString(DiffMissingStyle.Render(segment.Text))
+		case data.DiffExtra:
+			diff.WriteString(DiffExtraStyle.Render(segment.Text))
+		}
+	}
+
+	verdict := "✗ wrong"
+	switch {
+	case result.Exact:
+		verdict = "✓ correct"
+	case result.Grade() == data.GradeHard:
+		verdict = "~ close"
+	}
+
+	return Instructions.Render(fmt.Sprintf("Your answer: %s  %s (%.0f%%)",
+		diff.String(), verdict, result.Similarity*100))
+}
+
+// SearchSnippetView renders a search result snippet with the matched words
+// highlighted.
+func SearchSnippetView(snippet string, highlights []data.Span) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range highlights {
+		b.WriteString(HelpStyle.Render(snippet[last:span.Start]))
+		b.WriteString(SearchHighlightStyle.Render(snippet[span.Start:span.End]))
+		last = span.End
+	}
+	b.WriteString(HelpStyle.Render(snippet[last:]))
+	return b.String()
 }
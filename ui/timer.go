@@ -0,0 +1,100 @@
+// ui/timer.go
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"go-flashcards/data"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Choices for the time settings, cycled through in the settings screen. Zero
+// turns the setting off.
+var (
+	cardTimeLimits = []int{0, 10, 20, 30, 60}    // seconds
+	sessionLengths = []int{0, 5, 10, 15, 25, 45} // minutes
+)
+
+// timerTickMsg updates the study timers once a second.
+type timerTickMsg time.Time
+
+func tickTimer() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return timerTickMsg(t)
+	})
+}
+
+// timersActive reports whether a card is on screen with a stopwatch, a time
+// limit or a time box running.
+func (m model) timersActive() bool {
+	if m.mode != ModeViewCard || m.nothingToStudy() {
+		return false
+	}
+	return m.settings.ShowTimer || m.settings.CardTimeLimit > 0 || !m.studyDeadline.IsZero()
+}
+
+// beginStudy starts the time box of a new study session.
+func (m *model) beginStudy() {
+	now := time.Now()
+	m.studyStarted = now
+	m.studyReviewed = 0
+	m.studyAnswerTotal = 0
+	m.timeUp = false
+
+	m.studyDeadline = time.Time{}
+	if m.settings.SessionMinutes > 0 {
+		m.studyDeadline = now.Add(time.Duration(m.settings.SessionMinutes) * time.Minute)
+	}
+}
+
+// checkTimeLimit flips or fails the card on screen once its time limit has
+// passed without an answer.
+func (m *model) checkTimeLimit(now time.Time) {
+	limit := time.Duration(m.settings.CardTimeLimit) * time.Second
+	if limit == 0 || m.showAnswer || now.Sub(m.cardShownAt) < limit {
+		return
+	}
+
+	if m.settings.TimeLimitAction == data.TimeLimitFail {
+		m.answerTime = limit
+		m.gradeCurrentCard(data.GradeAgain)
+		m.timerNotice = "Time's up: the last card was marked again"
+		return
+	}
+
+	if m.typing {
+		m.submitTypedAnswer()
+	} else {
+		m.flipCard()
+	}
+}
+
+// elapsed returns how long the card on screen has been shown, stopping when
+// the answer is revealed.
+func (m model) elapsed(now time.Time) time.Duration {
+	if m.answerTime != 0 {
+		return m.answerTime
+	}
+	return now.Sub(m.cardShownAt)
+}
+
+// nextOption returns the value after current in options, wrapping around.
+func nextOption(options []int, current int) int {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// formatClock formats a duration as m:ss.
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
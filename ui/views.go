@@ -3,10 +3,16 @@ package ui
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"time"
+
+	"go-flashcards/data"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/uuid"
 )
 
 // View renders the UI
@@ -22,15 +28,28 @@ func (m model) View() string {
 		content = m.ViewCard()
 	case ModeCreateDeck:
 		content = m.ViewCreateDeck()
-	case ModeCreateCard:
+	case ModeCreateCard, ModeEditCard:
 		content = m.ViewCreateCard()
 	case ModeConfirmDelete:
 		content = m.ViewConfirmDelete()
+	case ModeStats:
+		content = m.ViewStats()
+	case ModeDeckProperties:
+		content = m.ViewDeckProperties()
+	case ModeBrowseCards:
+		content = m.ViewBrowseCards()
+	case ModeSearch:
+		content = m.ViewSearch()
+	case ModeFilteredDeck:
+		content = m.ViewFilteredDeck()
 	}
 
 	return AppStyle.Render(content)
 }
 
+// settingsCount is the number of rows in the settings screen.
+const settingsCount = 10
+
 func (m model) ViewSettings() string {
 
 	title := TitleStyle.
@@ -44,12 +63,19 @@ func (m model) ViewSettings() string {
 	items := []string{
 		fmt.Sprintf("Chaos Mode: %s", formatBoolSetting(m.settings.ChaosMode)),
 		fmt.Sprintf("Show Timer: %s", formatBoolSetting(m.settings.ShowTimer)),
-		fmt.Sprintf("Sound Effects: %s", formatBoolSetting(m.settings.Audio)),
+		fmt.Sprintf("Sound Effects: %s", formatAudioSetting(m.settings.Audio, m.canPlay())),
+		fmt.Sprintf("Scheduler: %s", formatSchedulerSetting(m.settings.Scheduler)),
+		fmt.Sprintf("Typed Answers: %s", formatBoolSetting(m.settings.TypedAnswer)),
+		fmt.Sprintf("Card Time Limit: %s", formatAmountSetting(m.settings.CardTimeLimit, "s")),
+		fmt.Sprintf("When Time Runs Out: %s", formatTimeLimitAction(m.settings.TimeLimitAction)),
+		fmt.Sprintf("Session Length: %s", formatAmountSetting(m.settings.SessionMinutes, " min")),
+		fmt.Sprintf("Morse Speed: %s", formatAmountSetting(m.settings.Morse.WPM, " wpm")),
+		fmt.Sprintf("Morse Farnsworth Speed: %s", formatAmountSetting(m.settings.Morse.FarnsworthWPM, " wpm")),
 	}
 
 	numSettings := len(items)
 	for i, item := range items {
-		if m.list.Cursor() == i {
+		if m.settingsCursor == i {
 			settingsContent.WriteString(SelectedSettingStyle.
 				Render("➤ " + item))
 		} else {
@@ -114,10 +140,54 @@ func (m model) ViewDeckList() string {
 func (m model) ViewCard() string {
 	helpContent := m.getHelpView()
 
-	if m.currentDeck == nil || len(m.currentDeck.Cards) == 0 {
+	if m.timeUp {
+		title := TitleStyle.Render("Time's up!")
+		doneMessage := CardStyle.Render(fmt.Sprintf("You studied for %s", formatClock(time.Since(m.studyStarted))))
+
+		summary := "No cards reviewed"
+		if m.studyReviewed > 0 {
+			average := m.studyAnswerTotal / time.Duration(m.studyReviewed)
+			summary = fmt.Sprintf("Reviewed %d · %.1fs per answer", m.studyReviewed, average.Seconds())
+		}
+
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			title,
+			doneMessage,
+			Instructions.Render(summary),
+			helpContent,
+		)
+	}
+
+	if m.session != nil && m.session.Remaining() == 0 {
+		counterView := SessionCounterView(m.session)
+		title := TitleStyle.Render(m.session.Name)
+		doneMessage := CardStyle.Render("Session complete!")
+
+		var instructions string
+		switch m.session.Total {
+		case 0:
+			instructions = Instructions.Render("No cards to review right now")
+		case 1:
+			instructions = Instructions.Render("Reviewed 1 card")
+		default:
+			instructions = Instructions.Render(fmt.Sprintf("Reviewed %d cards", m.session.Total))
+		}
+
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			counterView,
+			title,
+			doneMessage,
+			instructions,
+			helpContent,
+		)
+	}
+
+	if m.session == nil && (m.currentDeck == nil || len(m.currentDeck.Cards) == 0) {
 		// Custom view for empty decks
-		counterView := CardCounterView(0, 0)
-		title := TitleStyle.Render(m.currentDeck.Name)
+		counterView := CardCounterView(0, 0, 0)
+		title := DeckTitleView(m.currentDeck)
 		emptyMessage := CardStyle.Render("This deck has no cards yet.")
 		instructions := Instructions.Render("Press 'c' to create your first card")
 
@@ -132,13 +202,57 @@ func (m model) ViewCard() string {
 		return content
 	}
 
-	card := m.currentDeck.CurrentCard()
+	now := time.Now()
+	dueCount := m.currentDeck.DueCount(now)
+
+	if m.session == nil && dueCount == 0 {
+		// Nothing left to review in this session
+		counterView := CardCounterView(0, len(m.currentDeck.Cards), 0)
+		title := DeckTitleView(m.currentDeck)
+		doneMessage := CardStyle.Render("All caught up! No cards are due right now.")
+
+		nextMessage := ""
+		if next, ok := m.currentDeck.NextDue(now); ok {
+			nextMessage = "Next card due " + formatDue(next, now)
+		}
+		instructions := Instructions.Render(nextMessage)
+
+		return lipgloss.JoinVertical(
+			lipgloss.Center,
+			counterView,
+			title,
+			doneMessage,
+			instructions,
+			helpContent,
+		)
+	}
+
+	card, itemKey := m.studyItem()
 	if card == nil {
 		return "No cards in this deck."
 	}
 
-	counterView := CardCounterView(m.currentDeck.CurrentID+1, len(m.currentDeck.Cards))
-	deckTitle := TitleStyle.Render(m.currentDeck.Name)
+	counterView := CardCounterView(m.currentDeck.CurrentID+1, len(m.currentDeck.Cards), dueCount)
+	if m.session != nil {
+		counterView = SessionCounterView(m.session)
+	} else if m.currentDeck.ShuffleSeed != 0 {
+		// Card positions mean nothing in a shuffled order; count items in
+		// the order they are studied instead
+		pos, total := m.currentDeck.StudyPosition()
+		counterView = lipgloss.JoinVertical(lipgloss.Center,
+			CardCounterView(pos+1, total, dueCount),
+			CounterStyle.Render("shuffled"))
+	}
+	if itemKey == data.ReverseKey {
+		counterView = lipgloss.JoinVertical(lipgloss.Center, counterView, CounterStyle.Render("answer → question"))
+	}
+	stopwatch := m.settings.ShowTimer || m.settings.CardTimeLimit > 0
+	if timeBox := !m.studyDeadline.IsZero(); stopwatch || timeBox {
+		limit := time.Duration(m.settings.CardTimeLimit) * time.Second
+		timer := TimerView(m.elapsed(now), limit, m.studyDeadline.Sub(now), stopwatch, timeBox)
+		counterView = lipgloss.JoinVertical(lipgloss.Center, counterView, timer)
+	}
+	deckTitle := DeckTitleView(m.currentDeck)
 
 	if m.mode == ModeConfirmRemoveCard {
 		// Create a warning box with thick red borders
@@ -148,7 +262,7 @@ func (m model) ViewCard() string {
 			MarginTop(1).
 			Width(60)
 
-		question := card.Question
+		question := firstLine(card.Question)
 		if len(question) > 30 {
 			question = question[:27] + "..."
 		}
@@ -169,7 +283,84 @@ func (m model) ViewCard() string {
 
 	}
 
-	cardContent := CardContentView(card.Question, card.Answer, card.Tags, m.showAnswer)
+	question, answer := card.Question, card.Answer
+	if itemKey == data.ReverseKey {
+		question, answer = card.Answer, card.Question
+	}
+	plain := m.currentDeck.PlainText
+	if card.IsCloze() {
+		index := data.ClozeIndex(itemKey)
+		if m.showAnswer {
+			question = data.ClozeBack(card.Question, index, func(fill string) string {
+				if !plain {
+					// Strong text is styled like ClozeFillStyle
+					return "**" + fill + "**"
+				}
+				return ClozeFillStyle.Render(fill)
+			})
+		} else {
+			question = data.ClozeFront(card.Question, index)
+		}
+	}
+
+	if m.morse {
+		// The question is heard rather than read until the card is revealed
+		code := MorseCodeStyle.Render(data.EncodeMorse(card.Question))
+		switch {
+		case m.showAnswer:
+			question = RenderFace(QuestionStyle, data.MorseText(card.Question), true) + "\n\n" + code
+		case !m.canPlay():
+			question = QuestionStyle.Render("No audio player found, so read the code:") + "\n\n" + code
+		default:
+			question = QuestionStyle.Render("🔊 Listen and type what you hear")
+		}
+	} else {
+		question = RenderFace(QuestionStyle, question, plain)
+	}
+	if answer != "" {
+		answer = RenderFace(AnswerStyle, answer, plain)
+	}
+
+	var cardContent string
+	if m.typing {
+		cardContent = TypedContentView(question, m.typedInput.View(), card.Tags)
+	} else if m.choices != nil {
+		cardContent = ChoiceContentView(question, m.choices, m.choiceAnswer, m.choicePicked, card.Tags, m.showAnswer)
+	} else {
+		cardContent = CardContentView(question, answer, card.Tags, m.showAnswer)
+	}
+
+	if m.answerCheck != nil && m.showAnswer {
+		cardContent = lipgloss.JoinVertical(
+			lipgloss.Center,
+			cardContent,
+			AnswerDiffView(*m.answerCheck),
+		)
+	}
+
+	if m.editorErr != "" {
+		cardContent = lipgloss.JoinVertical(
+			lipgloss.Center,
+			cardContent,
+			RedMessageStyle.Render(m.editorErr),
+		)
+	}
+
+	if m.audioNotice != "" {
+		cardContent = lipgloss.JoinVertical(
+			lipgloss.Center,
+			cardContent,
+			RedMessageStyle.Render(m.audioNotice),
+		)
+	}
+
+	if m.timerNotice != "" {
+		cardContent = lipgloss.JoinVertical(
+			lipgloss.Center,
+			cardContent,
+			RedMessageStyle.Render(m.timerNotice),
+		)
+	}
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Center,
@@ -204,7 +395,11 @@ func (m model) ViewCreateDeck() string {
 }
 
 func (m model) ViewCreateCard() string {
-	title := TitleStyle.MarginLeft(2).Render("Add card to " + m.currentDeck.Name)
+	heading := "Add card to " + m.currentDeck.Name
+	if m.mode == ModeEditCard {
+		heading = "Edit card in " + m.currentDeck.Name
+	}
+	title := TitleStyle.MarginLeft(2).Render(heading)
 	helpView := m.getHelpView()
 
 	leftMargin := lipgloss.NewStyle().PaddingLeft(2)
@@ -215,9 +410,14 @@ func (m model) ViewCreateCard() string {
 	answerLabel := leftMargin.Render("Answer:")
 	answerInput := leftMargin.Render(m.answerInput.View())
 
-	tagsLabel := leftMargin.Render("Tags (comma-separated):")
+	tagsLabel := leftMargin.Render("Tags (comma-separated, enter to save):")
 	tagsInput := leftMargin.Render(m.tagsInput.View())
 
+	var editorErr string
+	if m.editorErr != "" {
+		editorErr = leftMargin.Render(RedMessageStyle.Render(m.editorErr))
+	}
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
@@ -230,6 +430,7 @@ func (m model) ViewCreateCard() string {
 		"\n",
 		tagsLabel,
 		tagsInput,
+		editorErr,
 		"\n",
 		helpView,
 	)
@@ -237,11 +438,191 @@ func (m model) ViewCreateCard() string {
 	return content
 }
 
+func (m model) ViewDeckProperties() string {
+	title := TitleStyle.MarginLeft(2).Render("Deck properties")
+	helpView := m.getHelpView()
+
+	leftMargin := lipgloss.NewStyle().PaddingLeft(2)
+
+	labels := []string{"Name:", "Description:", "Author:", "Colour:", "Emoji:"}
+
+	rows := []string{title}
+	for i, input := range m.propertyInputs {
+		rows = append(rows,
+			leftMargin.Render(labels[i]),
+			leftMargin.Render(input.View()),
+		)
+	}
+	rows = append(rows, leftMargin.Render("Shuffle by default: "+formatBoolSetting(m.propertyShuffle)))
+
+	if deck := m.deckToEdit; deck != nil {
+		rows = append(rows,
+			"",
+			leftMargin.Render(StatLine("Cards", fmt.Sprintf("%d", len(deck.Cards)))),
+			leftMargin.Render(StatLine("Created", formatTimestamp(deck.Created))),
+			leftMargin.Render(StatLine("Updated", formatTimestamp(deck.Updated))),
+		)
+	}
+
+	if m.propertiesErr != "" {
+		rows = append(rows, leftMargin.Render(RedMessageStyle.Render(m.propertiesErr)))
+	}
+
+	rows = append(rows, "\n", helpView)
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (m model) ViewBrowseCards() string {
+	title := TitleStyle.MarginLeft(2).Render("Cards in " + m.currentDeck.Name)
+	helpView := m.getHelpView()
+
+	leftMargin := lipgloss.NewStyle().PaddingLeft(2)
+
+	filter := HelpStyle.Render("Press / to filter")
+	if m.filtering || m.browseFilter.Value() != "" {
+		filter = m.browseFilter.View()
+	}
+
+	count := fmt.Sprintf("%d of %d cards", len(m.browseIDs), len(m.currentDeck.Cards))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		leftMargin.Render(filter),
+		"",
+		leftMargin.Render(m.cardTable.View()),
+		leftMargin.Render(CounterStyle.Render(count)),
+		"\n",
+		helpView,
+	)
+
+	return content
+}
+
+func (m model) ViewSearch() string {
+	title := TitleStyle.MarginLeft(2).Render("Search all decks")
+	helpView := m.getHelpView()
+
+	leftMargin := lipgloss.NewStyle().PaddingLeft(2)
+	fieldNames := []string{
+		data.FieldQuestion: "question",
+		data.FieldAnswer:   "answer",
+		data.FieldTags:     "tags",
+	}
+
+	// Each result takes three lines; keep the cursor on screen
+	visible := max(1, (m.height-lipgloss.Height(helpView)-8)/3)
+	start := max(0, m.searchCursor-visible+1)
+	end := min(len(m.searchResults), start+visible)
+
+	var results []string
+	for i := start; i < end; i++ {
+		result := m.searchResults[i]
+
+		deck := result.Deck.Name
+		if result.Deck.Emoji != "" {
+			deck = result.Deck.Emoji + " " + deck
+		}
+		heading := deck + StatLabelStyle.Render(" · "+fieldNames[result.Field])
+		block := lipgloss.JoinVertical(
+			lipgloss.Left,
+			heading,
+			SearchSnippetView(result.Snippet, result.Highlights),
+		)
+
+		if i == m.searchCursor {
+			results = append(results, ListSelectedItem.Render(block))
+		} else {
+			results = append(results, ListItemStyle.Render(block))
+		}
+	}
+
+	var status string
+	switch {
+	case strings.TrimSpace(m.searchInput.Value()) == "":
+		status = "Type to search every deck"
+	case len(m.searchResults) == 0:
+		status = "No matching cards"
+	case len(m.searchResults) == data.SearchLimit:
+		status = fmt.Sprintf("Top %d matches", data.SearchLimit)
+	case len(m.searchResults) == 1:
+		status = "1 match"
+	default:
+		status = fmt.Sprintf("%d matches", len(m.searchResults))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		leftMargin.Render(m.searchInput.View()),
+		leftMargin.Render(CounterStyle.Render(status)),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, results...),
+		"\n",
+		helpView,
+	)
+
+	return content
+}
+
+func (m model) ViewFilteredDeck() string {
+	heading := "New filtered deck"
+	if m.filterToEdit != uuid.Nil {
+		heading = "Edit filtered deck"
+	}
+	title := TitleStyle.MarginLeft(2).Render(heading)
+	helpView := m.getHelpView()
+
+	leftMargin := lipgloss.NewStyle().PaddingLeft(2)
+
+	labels := []string{"Name:", "Query:"}
+
+	rows := []string{title}
+	for i, input := range m.filterInputs {
+		rows = append(rows,
+			leftMargin.Render(labels[i]),
+			leftMargin.Render(input.View()),
+		)
+	}
+
+	preview, err := m.filterPreview()
+	if err != nil {
+		preview = RedMessageStyle.Render(err.Error())
+	} else {
+		preview = CounterStyle.Render(preview)
+	}
+	rows = append(rows, leftMargin.Render(preview))
+
+	syntax := []string{
+		"tag:name  -tag:name  deck:\"Go*\"  is:due  is:new",
+		"lapses>3  reps<2  interval>=30  \"some text\"",
+	}
+	rows = append(rows, "", leftMargin.Render(HelpStyle.Render(strings.Join(syntax, "\n"))))
+
+	if m.filterErr != "" {
+		rows = append(rows, leftMargin.Render(RedMessageStyle.Render(m.filterErr)))
+	}
+
+	rows = append(rows, "\n", helpView)
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
 func (m model) ViewConfirmDelete() string {
 	title := TitleStyle.MarginLeft(2).Render("Confirm Deletion")
 
 	var confirmBox string
-	if m.deckToDelete != nil {
+	if m.filterToDelete != nil {
+		confirmBox = WarningStyleContainer.Render(
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				RedMessageStyle.Render("Are you sure you want to delete this filtered deck?\n"),
+				m.filterToDelete.Name,
+				RedMessageStyle.Render("Its cards stay in their decks.\n"),
+			),
+		)
+	} else if m.deckToDelete != nil {
 		confirmBox = WarningStyleContainer.Render(
 			lipgloss.JoinVertical(
 				lipgloss.Center,
@@ -278,6 +659,136 @@ func (m model) ViewConfirmDelete() string {
 	return content
 }
 
+func (m model) ViewStats() string {
+	decks := m.deckManager.GetAllDecks()
+	m.deckManager.SortDecksAlphabetical(decks)
+
+	scopeName := "All decks"
+	if m.statsScope > 0 && m.statsScope <= len(decks) {
+		decks = decks[m.statsScope-1 : m.statsScope]
+		scopeName = decks[0].Name
+	}
+
+	now := time.Now()
+	stats := data.ComputeStats(decks, m.reviewLog, now)
+
+	title := TitleStyle.MarginLeft(2).Render("Statistics · " + scopeName)
+
+	retention := "–"
+	if stats.MatureReviews > 0 {
+		retention = fmt.Sprintf("%.0f%%", stats.Retention*100)
+	}
+	avgAnswer := "–"
+	if stats.AverageAnswer > 0 {
+		avgAnswer = fmt.Sprintf("%.1fs", stats.AverageAnswer.Seconds())
+	}
+
+	lines := []string{
+		StatLine("Cards", fmt.Sprintf("%d (%d due)", stats.TotalCards, stats.DueNow)),
+		StatLine("Reviewed today", fmt.Sprintf("%d", stats.ReviewedToday)),
+		StatLine("Reviewed this week", fmt.Sprintf("%d", stats.ReviewedWeek)),
+		StatLine("Retention", retention),
+		StatLine("Average answer time", avgAnswer),
+		"",
+		StatLabelStyle.Render(fmt.Sprintf("Due in the next %d days", data.ForecastDays)),
+		ForecastView(stats.Forecast[:]),
+	}
+
+	if len(stats.Hardest) > 0 {
+		lines = append(lines, "", StatLabelStyle.Render("Hardest cards"))
+		for _, hard := range stats.Hardest {
+			question := firstLine(data.ClozeText(hard.Card.Question))
+			question = ansi.Truncate(question, 40, "...")
+			padding := strings.Repeat(" ", 40-ansi.StringWidth(question))
+			entry := fmt.Sprintf("%s%s %2d✗", question, padding, hard.Fails)
+			if m.statsScope == 0 {
+				entry += HelpStyle.Render("  " + hard.DeckName)
+			}
+			lines = append(lines, StatValueStyle.Render(entry))
+		}
+	}
+
+	statsBox := StatsContainer.Render(strings.Join(lines, "\n"))
+
+	streaks := fmt.Sprintf("Current streak: %s   Longest streak: %s",
+		StatValueStyle.Render(formatDays(stats.CurrentStreak)),
+		StatValueStyle.Render(formatDays(stats.LongestStreak)))
+	heatmapBox := StatsContainer.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		StatLabelStyle.Render("Reviews in the last year"),
+		HeatmapView(stats.Daily, data.StartOfDay(now)),
+		"",
+		StatLabelStyle.Render(streaks),
+	))
+
+	leftMargin := lipgloss.NewStyle().MarginLeft(2)
+	helpWithMargin := leftMargin.Render(m.getHelpView())
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		statsBox,
+		heatmapBox,
+		helpWithMargin,
+	)
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return "–"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func formatDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// formatDue describes a due date relative to now, e.g. "in 3 days".
+func formatDue(due, now time.Time) string {
+	d := due.Sub(now)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("in %d min", int(d.Minutes())+1)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("in %d h", int(d.Hours()))
+	default:
+		return fmt.Sprintf("in %d days", int(d.Hours()/24))
+	}
+}
+
+// formatStudied describes when a deck was last studied, e.g. "studied 3 days ago".
+func formatStudied(last, now time.Time) string {
+	if last.IsZero() {
+		return "never studied"
+	}
+
+	days := int(math.Round(data.StartOfDay(now).Sub(data.StartOfDay(last)).Hours() / 24))
+	switch {
+	case days <= 0:
+		return "studied today"
+	case days == 1:
+		return "studied yesterday"
+	case days < 7:
+		return fmt.Sprintf("studied %d days ago", days)
+	case last.Year() == now.Year():
+		return "studied " + last.Format("Jan 2")
+	default:
+		return "studied " + last.Format("Jan 2, 2006")
+	}
+}
+
 // CustomHelpView creates a neatly organized help view with keybindings
 func CustomHelpView(keys []key.Binding) string {
 